@@ -2,7 +2,10 @@ package v8
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
+	"runtime"
+	"strings"
 	"time"
 )
 
@@ -11,6 +14,22 @@ type DynamicObject struct {
 	Target     reflect.Value     // Target Go value.
 	SpecFields []specField       // Special fields defined by 'js-field' struct tag.
 	Properties []DynamicProperty // Dynamic properities.
+	Subclass   string            // Name of the JS subclass which created the object, or "".
+}
+
+// GetDynamicObject returns the Go side of an object created by a bound
+// type, or nil when the value isn't one. An object created by a JS subclass
+// of the bound type has a Subclass.
+func GetDynamicObject(value *Value) *DynamicObject {
+	if !value.IsObject() {
+		return nil
+	}
+	object := value.ToObject()
+	if object.InternalFieldCount() != 1 {
+		return nil
+	}
+	dyObj, _ := object.GetInternalField(0).(*DynamicObject)
+	return dyObj
 }
 
 // Dynamic object property.
@@ -22,13 +41,193 @@ type DynamicProperty struct {
 // Get special field index.
 func (dyObj *DynamicObject) GetSpecField(name string) int {
 	for _, field := range dyObj.SpecFields {
-		if field.Name == name {
+		if field.Name != "" && field.Name == name {
 			return field.Index
 		}
 	}
 	return -1
 }
 
+// Returns the special field info of a field index.
+func (dyObj *DynamicObject) specFieldOf(index int) (specField, bool) {
+	for _, field := range dyObj.SpecFields {
+		if field.Index == index {
+			return field, true
+		}
+	}
+	return specField{}, false
+}
+
+// Keys returns the names of the enumerable properties: the exported fields,
+// named by their 'js-field' struct tag when they have one, and the dynamic
+// properties. Methods and the fields with the 'hidden' tag option, like
+// `js-field:",hidden"`, are not enumerable.
+func (dyObj *DynamicObject) Keys() []string {
+	keys := make([]string, 0)
+
+	if value := reflect.Indirect(dyObj.Target); value.Kind() == reflect.Struct {
+		typeInfo := value.Type()
+		for i := 0; i < typeInfo.NumField(); i++ {
+			field := typeInfo.Field(i)
+			spec, isSpec := dyObj.specFieldOf(i)
+
+			// Embedded structs are replaced by their promoted fields.
+			if field.Anonymous && !isSpec {
+				if names := promotedFields(field.Type); names != nil {
+					for _, name := range names {
+						// Skip the shadowed, ambiguous and repeated fields.
+						promoted, exists := typeInfo.FieldByName(name)
+						if exists && promoted.Index[0] == i && !containsString(keys, name) {
+							keys = append(keys, name)
+						}
+					}
+					continue
+				}
+			}
+
+			// Skip unexported fields.
+			if field.PkgPath != "" {
+				continue
+			}
+
+			name := field.Name
+			if isSpec {
+				if spec.Hidden {
+					continue
+				}
+				if spec.Name != "" {
+					name = spec.Name
+				}
+			}
+			keys = append(keys, name)
+		}
+	}
+
+	for _, property := range dyObj.Properties {
+		if property.Name != "" {
+			keys = append(keys, property.Name)
+		}
+	}
+
+	return keys
+}
+
+// Returns the attributes of a property, and false when it doesn't exist.
+func (dyObj *DynamicObject) attributes(name string) (PropertyAttribute, bool) {
+	value := dyObj.Target
+
+	fieldIndex := dyObj.GetSpecField(name)
+	if fieldIndex == -1 {
+		if field, exists := reflect.Indirect(value).Type().FieldByName(name); exists {
+			if len(field.Index) > 1 {
+				// Promoted fields have no special fields.
+				return PA_None, true
+			}
+			fieldIndex = field.Index[0]
+		}
+	}
+
+	if fieldIndex != -1 {
+		if field, exists := dyObj.specFieldOf(fieldIndex); exists && field.Hidden {
+			return PA_DontEnum, true
+		}
+		return PA_None, true
+	}
+
+	if dyObj.GetDynamicProperty(name) != nil {
+		return PA_None, true
+	}
+
+	return PA_None, false
+}
+
+// Returns the exported fields promoted from an embedded struct, or nil
+// when the type isn't a struct.
+func promotedFields(typeInfo reflect.Type) []string {
+	if typeInfo.Kind() == reflect.Ptr {
+		typeInfo = typeInfo.Elem()
+	}
+	if typeInfo.Kind() != reflect.Struct {
+		return nil
+	}
+
+	names := make([]string, 0)
+	for i := 0; i < typeInfo.NumField(); i++ {
+		field := typeInfo.Field(i)
+		if embedded := embeddedStruct(field); embedded != nil {
+			names = append(names, promotedFields(embedded)...)
+			continue
+		}
+		if field.PkgPath == "" {
+			names = append(names, field.Name)
+		}
+	}
+	return names
+}
+
+// Returns the struct type of an embedded struct or struct pointer field,
+// or nil when the field isn't one.
+func embeddedStruct(field reflect.StructField) reflect.Type {
+	if !field.Anonymous {
+		return nil
+	}
+	typeInfo := field.Type
+	if typeInfo.Kind() == reflect.Ptr {
+		typeInfo = typeInfo.Elem()
+	}
+	if typeInfo.Kind() != reflect.Struct {
+		return nil
+	}
+	return typeInfo
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the field of a struct by name, promoted fields included, or an
+// invalid value when it doesn't exist. A nil embedded struct pointer is
+// allocated when alloc is true, otherwise the zero value of the field is
+// returned.
+func fieldByName(value reflect.Value, name string, alloc bool) reflect.Value {
+	value = reflect.Indirect(value)
+
+	field, exists := value.Type().FieldByName(name)
+	if !exists {
+		return reflect.Value{}
+	}
+
+	if value = fieldByIndex(value, field.Index, alloc); !value.IsValid() {
+		return reflect.Zero(field.Type)
+	}
+	return value
+}
+
+// Returns the field of a struct by its index, promoted fields included. A
+// nil embedded struct pointer is allocated when alloc is true, or an
+// invalid value is returned when it isn't, or when the pointer can't be
+// set because its embedded type is unexported.
+func fieldByIndex(value reflect.Value, index []int, alloc bool) reflect.Value {
+	for i, x := range index {
+		if i > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				if !alloc || !value.CanSet() {
+					return reflect.Value{}
+				}
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(x)
+	}
+	return value
+}
+
 // Set dynamic property. If the property not exists, it will be added.
 func (dyObj *DynamicObject) SetDynamicProperty(name string, jsvalue *Value) {
 	for i := 0; i < len(dyObj.Properties); i++ {
@@ -65,22 +264,161 @@ func (dyObj *DynamicObject) DelDynamicProperty(name string) {
 	}
 }
 
-func bindFuncCallback(callbackInfo FunctionCallbackInfo) {
-	engine := callbackInfo.CurrentScope().GetEngine()
+// A Go function bound to JS.
+type bindFunc struct {
+	Name  string        // Name used in errors.
+	Value reflect.Value // Go function.
+}
+
+func newBindFunc(name string, value reflect.Value) bindFunc {
+	if name == "" {
+		name = runtime.FuncForPC(value.Pointer()).Name()
+	}
+	return bindFunc{name, value}
+}
+
+// SetStrictBinding enables checking the arguments of bound Go functions.
+// A missing, extra or not convertible argument throws a TypeError instead
+// of being converted to the zero value.
+func (engine *Engine) SetStrictBinding(strict bool) {
+	engine.strictBinding = strict
+}
+
+// Returns the JS type name of a value used in errors.
+func jsTypeName(value *Value) string {
+	switch {
+	case value.IsUndefined():
+		return "undefined"
+	case value.IsNull():
+		return "null"
+	case value.IsBoolean():
+		return "boolean"
+	case value.IsNumber():
+		return "number"
+	case value.IsString():
+		return "string"
+	case value.IsArray():
+		return "array"
+	case value.IsFunction():
+		return "function"
+	case value.IsDate():
+		return "date"
+	case value.IsRegExp():
+		return "regexp"
+	}
+	return "object"
+}
+
+// Reports whether the value can be converted to the Go type by SetJsValueToGo.
+func isJsValueConvertible(goType reflect.Type, value *Value) bool {
+	switch goType {
+	case typeOfValue:
+		return true
+	case typeOfObject:
+		return value.IsObject()
+	case typeOfArray:
+		return value.IsArray()
+	case typeOfRegExp:
+		return value.IsRegExp()
+	case typeOfFunction:
+		return value.IsFunction()
+	case typeOfTime:
+		return value.IsDate()
+	}
+
+	switch goType.Kind() {
+	case reflect.Bool:
+		return value.IsBoolean()
+	case reflect.String:
+		return value.IsString()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return value.IsNumber()
+	case reflect.Slice, reflect.Array:
+		return value.IsArray()
+	case reflect.Map:
+		return value.IsObject()
+	case reflect.Func:
+		return value.IsFunction()
+	}
+	return true
+}
+
+// Returns the error of the arguments which don't match the Go function,
+// or "" when they match.
+func (engine *Engine) checkBindFuncArgs(fn bindFunc, callbackInfo FunctionCallbackInfo) string {
+	funcType := fn.Value.Type()
+	numIn := funcType.NumIn()
+	numArgs := callbackInfo.Length()
+
+	numFixed := numIn
+	if funcType.IsVariadic() {
+		numFixed--
+	}
+
+	if numArgs < numFixed {
+		return fmt.Sprintf("%s: missing argument %d of type %s", fn.Name, numArgs+1, funcType.In(numArgs))
+	}
+
+	if numArgs > numIn && !funcType.IsVariadic() {
+		return fmt.Sprintf("%s: unexpected argument %d, expects %d arguments", fn.Name, numIn+1, numIn)
+	}
+
+	for i := 0; i < numArgs; i++ {
+		var argType reflect.Type
+		if i < numFixed {
+			argType = funcType.In(i)
+		} else {
+			argType = funcType.In(numIn - 1).Elem()
+		}
+
+		// The custom conversions check the values themselves.
+		if engine.hasFromJs(argType) {
+			continue
+		}
+
+		if arg := callbackInfo.Get(i); !isJsValueConvertible(argType, arg) {
+			return fmt.Sprintf("%s: argument %d should be %s, not %s", fn.Name, i+1, argType, jsTypeName(arg))
+		}
+	}
+
+	return ""
+}
 
-	gofunc := callbackInfo.Data().(reflect.Value)
+// Calls a bound Go function with the JS arguments. The results don't have
+// the trailing error, and false is returned when an exception was thrown.
+func (engine *Engine) callBindFunc(fn bindFunc, callbackInfo FunctionCallbackInfo) ([]reflect.Value, bool) {
+	gofunc := fn.Value
 	funcType := gofunc.Type()
 
+	if engine.strictBinding {
+		if err := engine.checkBindFuncArgs(fn, callbackInfo); err != "" {
+			callbackInfo.CurrentScope().ThrowError(TypeError, err)
+			return nil, false
+		}
+	}
+
 	numIn := funcType.NumIn()
 	numArgs := callbackInfo.Length()
 
+	// Converts the argument i, the errors of the custom conversions are thrown.
+	setArg := func(arg reflect.Value, i int) bool {
+		if err := engine.SetJsValueToGoE(arg, callbackInfo.Get(i)); err != nil {
+			callbackInfo.CurrentScope().ThrowError(TypeError, fmt.Sprintf("%s: argument %d: %v", fn.Name, i+1, err))
+			return false
+		}
+		return true
+	}
+
 	var out []reflect.Value
 
 	in := make([]reflect.Value, numIn)
 	for i := 0; i < numIn-1; i++ {
-		jsvalue := callbackInfo.Get(i)
 		in[i] = reflect.Indirect(reflect.New(funcType.In(i)))
-		engine.SetJsValueToGo(in[i], jsvalue)
+		if !setArg(in[i], i) {
+			return nil, false
+		}
 	}
 
 	if funcType.IsVariadic() {
@@ -88,16 +426,18 @@ func bindFuncCallback(callbackInfo FunctionCallbackInfo) {
 		in[numIn-1] = reflect.MakeSlice(funcType.In(numIn-1), sliceLen, sliceLen)
 
 		for i := 0; i < sliceLen; i++ {
-			jsvalue := callbackInfo.Get(numIn - 1 + i)
-			engine.SetJsValueToGo(in[numIn-1].Index(i), jsvalue)
+			if !setArg(in[numIn-1].Index(i), numIn-1+i) {
+				return nil, false
+			}
 		}
 
 		out = gofunc.CallSlice(in)
 	} else {
 		if numIn > 0 {
-			jsvalue := callbackInfo.Get(numIn - 1)
 			in[numIn-1] = reflect.Indirect(reflect.New(funcType.In(numIn - 1)))
-			engine.SetJsValueToGo(in[numIn-1], jsvalue)
+			if !setArg(in[numIn-1], numIn-1) {
+				return nil, false
+			}
 		}
 
 		out = gofunc.Call(in)
@@ -105,6 +445,58 @@ func bindFuncCallback(callbackInfo FunctionCallbackInfo) {
 
 	if out == nil {
 		callbackInfo.CurrentScope().ThrowException("argument number not match")
+		return nil, false
+	}
+
+	// A trailing error is thrown when it isn't nil, and dropped otherwise.
+	if n := len(out); n > 0 && funcType.Out(n-1) == typeOfError {
+		if err := out[n-1]; !err.IsNil() {
+			callbackInfo.CurrentScope().ThrowException2(engine.NewGoError(err.Interface().(error)))
+			return nil, false
+		}
+		out = out[:n-1]
+	}
+
+	return out, true
+}
+
+func bindFuncCallback(callbackInfo FunctionCallbackInfo) {
+	engine := callbackInfo.CurrentScope().GetEngine()
+	engine.invokeBindFunc(callbackInfo.Data().(bindFunc), callbackInfo)
+}
+
+// A method of a bound type, it is called on the Go value of this.
+type bindMethod struct {
+	Name   string // Name used in errors, like "Type.Method".
+	Method string // Go method name.
+}
+
+func bindMethodCallback(callbackInfo FunctionCallbackInfo) {
+	engine := callbackInfo.CurrentScope().GetEngine()
+	method := callbackInfo.Data().(bindMethod)
+
+	var bindObj *DynamicObject
+	if this := callbackInfo.This(); this.InternalFieldCount() == 1 {
+		bindObj, _ = this.GetInternalField(0).(*DynamicObject)
+	}
+
+	var gofunc reflect.Value
+	if bindObj != nil {
+		gofunc = bindObj.Target.MethodByName(method.Method)
+	}
+
+	if !gofunc.IsValid() {
+		callbackInfo.CurrentScope().ThrowError(TypeError, method.Name+": illegal invocation")
+		return
+	}
+
+	engine.invokeBindFunc(bindFunc{method.Name, gofunc}, callbackInfo)
+}
+
+// Calls a bound Go function and returns its results to JS.
+func (engine *Engine) invokeBindFunc(fn bindFunc, callbackInfo FunctionCallbackInfo) {
+	out, ok := engine.callBindFunc(fn, callbackInfo)
+	if !ok {
 		return
 	}
 
@@ -127,14 +519,17 @@ func bindFuncCallback(callbackInfo FunctionCallbackInfo) {
 
 // Bind type info.
 type bindTypeInfo struct {
+	Name       string          // The JS name of the type.
 	Template   *ObjectTemplate // The object template.
 	SpecFields []specField     // Special fields defined by 'js-field' struct tag.
+	Auto       bool            // Bound on first use, not by Bind.
 }
 
 // Special field info.
 type specField struct {
-	Name  string
-	Index int
+	Name   string // JS name, or "" when the field isn't renamed.
+	Index  int
+	Hidden bool // Not enumerable.
 }
 
 //
@@ -153,131 +548,265 @@ func (template *ObjectTemplate) Bind(typeName string, target interface{}) error
 	}
 
 	if typeInfo.Kind() == reflect.Func {
-		goFunc := reflect.ValueOf(target)
+		goFunc := engine.NewFunctionTemplate(bindFuncCallback, newBindFunc(typeName, reflect.ValueOf(target)))
 		template.SetAccessor(typeName, func(name string, info AccessorCallbackInfo) {
-			info.ReturnValue().Set(engine.NewFunction(bindFuncCallback, goFunc).Value)
+			info.ReturnValue().Set(goFunc.NewFunction())
 		}, nil, nil, PA_None)
 		return nil
 	}
 
 	if typeInfo.Kind() == reflect.Struct {
-		if _, exists := engine.bindTypes[typeInfo]; exists {
-			return errors.New("duplicate type binding")
+		return template.bindStruct(typeName, typeInfo, nil)
+	}
+
+	return errors.New("unsupported target type")
+}
+
+// Binds a struct type, the instances are created by the factory function
+// when it isn't nil.
+func (template *ObjectTemplate) bindStruct(typeName string, typeInfo reflect.Type, factory *bindFunc) error {
+	engine := template.engine
+
+	if bindInfo, exists := engine.bindTypes[typeInfo]; exists && !bindInfo.Auto {
+		return errors.New("duplicate type binding")
+	}
+
+	constructor := engine.newBindType(typeName, typeInfo, factory)
+
+	template.SetAccessor(typeName, func(name string, info AccessorCallbackInfo) {
+		info.ReturnValue().Set(constructor.NewFunction())
+	}, nil, nil, PA_None)
+
+	return nil
+}
+
+// Returns the binding of a struct type, the types which aren't bound are
+// bound on first use, like the types of nested structs.
+func (engine *Engine) bindTypeOf(typeInfo reflect.Type) (bindTypeInfo, bool) {
+	if bindInfo, exists := engine.bindTypes[typeInfo]; exists {
+		return bindInfo, true
+	}
+
+	switch typeInfo {
+	case typeOfTime, typeOfLiveMap, typeOfLiveSlice:
+		return bindTypeInfo{}, false
+	}
+	if typeInfo.Kind() != reflect.Struct {
+		return bindTypeInfo{}, false
+	}
+
+	typeName := typeInfo.Name()
+	if typeName == "" {
+		typeName = "Object"
+	}
+	engine.newBindType(typeName, typeInfo, nil)
+
+	bindInfo := engine.bindTypes[typeInfo]
+	bindInfo.Auto = true
+	engine.bindTypes[typeInfo] = bindInfo
+
+	return bindInfo, true
+}
+
+// Creates the class of a struct type.
+func (engine *Engine) newBindType(typeName string, typeInfo reflect.Type, factory *bindFunc) *FunctionTemplate {
+	// Take special fields
+	specFields := make([]specField, 0)
+	for i := 0; i < typeInfo.NumField(); i++ {
+		if tag := typeInfo.Field(i).Tag.Get("js-field"); tag != "" {
+			options := strings.Split(tag, ",")
+			field := specField{Name: options[0], Index: i}
+			for _, option := range options[1:] {
+				if option == "hidden" {
+					field.Hidden = true
+				}
+			}
+			specFields = append(specFields, field)
 		}
+	}
 
-		// Take special fields
-		specFields := make([]specField, 0)
-		for i := 0; i < typeInfo.NumField(); i++ {
-			if name := typeInfo.Field(i).Tag.Get("js-field"); name != "" {
-				specFields = append(specFields, specField{name, i})
+	constructor := engine.NewFunctionTemplate(func(info FunctionCallbackInfo) {
+		newTarget := info.NewTarget()
+		if !newTarget.IsObject() {
+			info.CurrentScope().ThrowError(TypeError, typeName+": class constructor cannot be invoked without 'new'")
+			return
+		}
+
+		target := reflect.New(typeInfo)
+
+		if factory != nil {
+			out, ok := engine.callBindFunc(*factory, info)
+			if !ok {
+				return
+			}
+			if out[0].IsNil() {
+				info.CurrentScope().ThrowError(TypeError, factory.Name+": returned nil")
+				return
 			}
+			target = out[0]
 		}
 
-		constructor := engine.NewFunctionTemplate(func(info FunctionCallbackInfo) {
-			info.This().SetInternalField(0, &DynamicObject{
-				Target: reflect.New(typeInfo),
-			})
-		}, nil)
-		constructor.SetClassName(typeName)
-
-		objTemplate := constructor.InstanceTemplate()
-		objTemplate.SetInternalFieldCount(1)
-		objTemplate.SetNamedPropertyHandler(
-			// get
-			func(name string, info PropertyCallbackInfo) {
-				bindObj := info.This().GetInternalField(0).(*DynamicObject)
-				value := bindObj.Target
-
-				// Try to get field by special fields.
-				if fieldIndex := bindObj.GetSpecField(name); fieldIndex != -1 {
-					if field := reflect.Indirect(value).Field(fieldIndex); field.IsValid() {
-						info.ReturnValue().Set(engine.GoValueToJsValue(field))
-						return
-					}
-				}
+		bindObj := &DynamicObject{
+			Target:     target,
+			SpecFields: specFields,
+		}
+
+		// A JS subclass calls the constructor by super().
+		if !newTarget.StrictEquals(info.Callee().Value) {
+			bindObj.Subclass = newTarget.ToObject().GetProperty("name").ToString()
+		}
 
-				// Try to get field by type info
-				if field := reflect.Indirect(value).FieldByName(name); field.IsValid() {
+		info.This().SetInternalField(0, bindObj)
+	}, nil)
+	constructor.SetClassName(typeName)
+
+	// Methods are shared by the instances, so they keep their identity.
+	protoTemplate := constructor.PrototypeTemplate()
+	ptrType := reflect.PtrTo(typeInfo)
+	for i := 0; i < ptrType.NumMethod(); i++ {
+		name := ptrType.Method(i).Name
+		method := engine.NewFunctionTemplate(bindMethodCallback, bindMethod{typeName + "." + name, name})
+		protoTemplate.SetFunctionTemplate(name, method, PA_DontEnum)
+	}
+
+	objTemplate := constructor.InstanceTemplate()
+	objTemplate.SetInternalFieldCount(1)
+	objTemplate.SetNamedPropertyHandler(
+		// get
+		func(name string, info PropertyCallbackInfo) {
+			bindObj := info.Holder().GetInternalField(0).(*DynamicObject)
+			value := bindObj.Target
+
+			// Try to get field by special fields.
+			if fieldIndex := bindObj.GetSpecField(name); fieldIndex != -1 {
+				if field := reflect.Indirect(value).Field(fieldIndex); field.IsValid() {
 					info.ReturnValue().Set(engine.GoValueToJsValue(field))
 					return
 				}
+			}
 
-				// Try to call method by type info
-				if method := value.MethodByName(name); method.IsValid() {
-					info.ReturnValue().Set(engine.NewFunction(bindFuncCallback, method).Value)
-					return
-				}
+			// Try to get field by type info, promoted fields included.
+			if field := fieldByName(value, name, false); field.IsValid() {
+				info.ReturnValue().Set(engine.GoValueToJsValue(field))
+				return
+			}
 
-				// Maybe this is a dynamic property
-				jsvalue := bindObj.GetDynamicProperty(name)
-				if jsvalue != nil {
-					info.ReturnValue().Set(jsvalue)
-					return
-				}
+			// Maybe this is a dynamic property, methods are on the prototype.
+			jsvalue := bindObj.GetDynamicProperty(name)
+			if jsvalue != nil {
+				info.ReturnValue().Set(jsvalue)
+				return
+			}
 
-				info.CurrentScope().ThrowException("Could't found property or method '" + typeName + "." + name + "' when set value")
-			},
-			// set
-			func(name string, jsvalue *Value, info PropertyCallbackInfo) {
-				bindObj := info.This().GetInternalField(0).(*DynamicObject)
-				value := bindObj.Target
-
-				// Try to set field by special fields.
-				if fieldIndex := bindObj.GetSpecField(name); fieldIndex != -1 {
-					if field := reflect.Indirect(value).Field(fieldIndex); field.IsValid() {
-						engine.SetJsValueToGo(field, jsvalue)
-						return
-					}
-				}
+			// Not found, look up the prototype chain, like for toString or toJSON.
+		},
+		// set
+		func(name string, jsvalue *Value, info PropertyCallbackInfo) {
+			holder := info.Holder()
+			bindObj := holder.GetInternalField(0).(*DynamicObject)
+			value := bindObj.Target
 
-				// Try to set field by type info
-				if field := reflect.Indirect(value).FieldByName(name); field.IsValid() {
-					engine.SetJsValueToGo(field, jsvalue)
+			// Sets a field, the errors of the custom conversions are thrown.
+			setField := func(field reflect.Value) {
+				if err := engine.SetJsValueToGoE(field, jsvalue); err != nil {
+					info.CurrentScope().ThrowError(TypeError, fmt.Sprintf("%s.%s: %v", typeName, name, err))
 					return
 				}
+				info.ReturnValue().Set(jsvalue)
+			}
 
-				// This is a dynamic property
-				bindObj.SetDynamicProperty(name, jsvalue)
-			},
-			// query
-			func(name string, info PropertyCallbackInfo) {
-				bindObj := info.This().ToObject().GetInternalField(0).(*DynamicObject)
-				value := bindObj.Target
-
-				// Is it a field or a method?
-				if reflect.Indirect(value).FieldByName(name).IsValid() || value.MethodByName(name).IsValid() {
-					info.ReturnValue().SetBoolean(true)
+			// Try to set field by special fields.
+			if fieldIndex := bindObj.GetSpecField(name); fieldIndex != -1 {
+				if field := reflect.Indirect(value).Field(fieldIndex); field.IsValid() {
+					setField(field)
 					return
 				}
+			}
 
-				// Is it dynamic property?
-				info.ReturnValue().SetBoolean(bindObj.GetDynamicProperty(name) != nil)
-			},
-			// delete
-			func(name string, info PropertyCallbackInfo) {
-				bindObj := info.This().ToObject().GetInternalField(0).(*DynamicObject)
-
-				// only dynamic can deleted
-				bindObj.DelDynamicProperty(name)
-			},
-			// enum
-			nil,
-			// data
-			nil,
-		)
-		engine.bindTypes[typeInfo] = bindTypeInfo{objTemplate, specFields}
+			// Try to set field by type info, promoted fields included.
+			if field := fieldByName(value, name, true); field.IsValid() {
+				setField(field)
+				return
+			}
 
-		template.SetAccessor(typeName, func(name string, info AccessorCallbackInfo) {
-			info.ReturnValue().Set(constructor.NewFunction())
-		}, nil, nil, PA_None)
+			// Let V8 set the properties of the prototype chain, like the
+			// setters of a JS subclass.
+			if bindObj.GetDynamicProperty(name) == nil && holder.GetPrototype().HasProperty(name) {
+				return
+			}
 
-		return nil
+			// This is a dynamic property, it is intercepted or V8 defines
+			// an own property too.
+			bindObj.SetDynamicProperty(name, jsvalue)
+			info.ReturnValue().Set(jsvalue)
+		},
+		// query
+		func(name string, info PropertyCallbackInfo) {
+			bindObj := info.Holder().GetInternalField(0).(*DynamicObject)
+
+			// Is it a field, a method or a dynamic property?
+			if attribs, exists := bindObj.attributes(name); exists {
+				info.ReturnValue().SetInt32(int32(attribs))
+			}
+		},
+		// delete
+		func(name string, info PropertyCallbackInfo) {
+			bindObj := info.Holder().GetInternalField(0).(*DynamicObject)
+
+			// only dynamic can deleted
+			bindObj.DelDynamicProperty(name)
+		},
+		// enum
+		func(info PropertyCallbackInfo) {
+			bindObj := info.Holder().GetInternalField(0).(*DynamicObject)
+
+			keys := bindObj.Keys()
+			array := engine.NewArray(len(keys))
+			for i, key := range keys {
+				array.ToObject().SetElement(i, engine.NewString(key))
+			}
+			info.ReturnValue().Set(array)
+		},
+		// data
+		nil,
+	)
+	engine.bindTypes[typeInfo] = bindTypeInfo{Name: typeName, Template: objTemplate, SpecFields: specFields}
+
+	return constructor
+}
+
+// BindClass binds a struct type to JS like Bind, but the instances are
+// created by a Go factory function which receives the constructor arguments,
+// like `func NewPoint(x, y float64) *Point`. The factory must return a
+// pointer to the struct, optionally followed by an error which is thrown
+// when it isn't nil.
+func (template *ObjectTemplate) BindClass(className string, factory interface{}) error {
+	factoryValue := reflect.ValueOf(factory)
+	factoryType := factoryValue.Type()
+
+	if factoryType.Kind() != reflect.Func {
+		return errors.New("factory should be a function")
 	}
 
-	return errors.New("unsupported target type")
+	numOut := factoryType.NumOut()
+	if numOut < 1 || numOut > 2 || numOut == 2 && factoryType.Out(1) != typeOfError {
+		return errors.New("factory should return a struct pointer and an optional error")
+	}
+
+	typeInfo := factoryType.Out(0)
+	if typeInfo.Kind() != reflect.Ptr || typeInfo.Elem().Kind() != reflect.Struct {
+		return errors.New("factory should return a struct pointer and an optional error")
+	}
+
+	fn := newBindFunc(className, factoryValue)
+	return template.bindStruct(className, typeInfo.Elem(), &fn)
 }
 
 func (engine *Engine) GoValueToJsValue(value reflect.Value) *Value {
+	if jsvalue, converted := engine.convertToJs(value); converted {
+		return jsvalue
+	}
+
 	switch value.Kind() {
 	case reflect.Bool:
 		return engine.NewBoolean(value.Bool())
@@ -293,7 +822,7 @@ func (engine *Engine) GoValueToJsValue(value reflect.Value) *Value {
 		return engine.NewNumber(float64(value.Uint()))
 	case reflect.Float32, reflect.Float64:
 		return engine.NewNumber(value.Float())
-	// TODO: avoid data copy
+	// LiveSlice avoids the data copy.
 	case reflect.Array, reflect.Slice:
 		arrayLen := value.Len()
 		jsArrayVal := engine.NewArray(value.Len())
@@ -302,7 +831,7 @@ func (engine *Engine) GoValueToJsValue(value reflect.Value) *Value {
 			jsArray.SetElement(i, engine.GoValueToJsValue(value.Index(i)))
 		}
 		return jsArrayVal
-	// TODO: avoid data copy
+	// LiveMap avoids the data copy.
 	case reflect.Map:
 		jsObjectVal := engine.NewObject()
 		jsObject := jsObjectVal.ToObject()
@@ -318,54 +847,104 @@ func (engine *Engine) GoValueToJsValue(value reflect.Value) *Value {
 		}
 		return jsObjectVal
 	case reflect.Func:
-		return engine.NewFunction(bindFuncCallback, value).Value
+		return engine.NewFunction(bindFuncCallback, newBindFunc("", value)).Value
 	case reflect.Interface:
-		return engine.GoValueToJsValue(reflect.ValueOf(value.Interface()))
+		// Convert by the dynamic type.
+		return engine.GoValueToJsValue(value.Elem())
 	case reflect.Ptr:
-		valType := value.Type()
-		if valType == typeOfValue {
+		switch value.Type() {
+		case typeOfValue:
 			return value.Interface().(*Value)
-		}
-		elemType := valType.Elem()
-		if elemType.Kind() == reflect.Struct {
-			if bindInfo, exits := engine.bindTypes[elemType]; exits {
-				objectVal := engine.NewInstanceOf(bindInfo.Template)
-				object := objectVal.ToObject()
-				object.SetInternalField(0, &DynamicObject{
-					Target:     value,
-					SpecFields: bindInfo.SpecFields,
-				})
-				return objectVal
+		case typeOfObject, typeOfArray, typeOfRegExp, typeOfFunction:
+			if !value.IsNil() {
+				return reflect.Indirect(value).FieldByName("Value").Interface().(*Value)
 			}
 		}
+		if value.IsNil() {
+			return engine.Null()
+		}
+		if bindInfo, exists := engine.bindTypeOf(value.Type().Elem()); exists {
+			return engine.newBindObject(bindInfo, value)
+		}
+		return engine.GoValueToJsValue(value.Elem())
 	case reflect.Struct:
-		switch value.Interface().(type) {
-		case time.Time:
+		switch value.Type() {
+		case typeOfTime:
 			return engine.NewDate(value.Interface().(time.Time))
-		default:
-			if bindInfo, exits := engine.bindTypes[value.Type()]; exits {
-				objectVal := engine.NewInstanceOf(bindInfo.Template)
-				object := objectVal.ToObject()
-				object.SetInternalField(0, &DynamicObject{
-					Target:     value,
-					SpecFields: bindInfo.SpecFields,
-				})
-				return objectVal
+		case typeOfLiveMap:
+			return engine.newLiveMap(reflect.ValueOf(value.Interface().(LiveMap).Map))
+		case typeOfLiveSlice:
+			return engine.newLiveSlice(reflect.ValueOf(value.Interface().(LiveSlice).Slice), nil)
+		}
+		if bindInfo, exists := engine.bindTypeOf(value.Type()); exists {
+			// Nested structs share the parent value, other values are copied.
+			if value.CanAddr() {
+				return engine.newBindObject(bindInfo, value.Addr())
 			}
+			target := reflect.New(value.Type())
+			target.Elem().Set(value)
+			return engine.newBindObject(bindInfo, target)
 		}
 	}
 	return engine.Undefined()
 }
 
+// Creates an object of a bound type.
+func (engine *Engine) newBindObject(bindInfo bindTypeInfo, target reflect.Value) *Value {
+	objectVal := engine.NewInstanceOf(bindInfo.Template)
+	objectVal.ToObject().SetInternalField(0, &DynamicObject{
+		Target:     target,
+		SpecFields: bindInfo.SpecFields,
+	})
+	return objectVal
+}
+
+// NewGoError creates an Error with the message of err. The cause property
+// of the Error is err converted by GoValueToJsValue, or its message when
+// the type of err isn't bound.
+func (engine *Engine) NewGoError(err error) *Value {
+	jsErr := engine.NewError(err.Error())
+
+	errType := reflect.TypeOf(err)
+	if errType.Kind() == reflect.Ptr {
+		errType = errType.Elem()
+	}
+
+	// Only the bound types, errors.New values would be bound on first use.
+	cause := engine.NewString(err.Error())
+	if bindInfo, exists := engine.bindTypes[errType]; exists && !bindInfo.Auto {
+		cause = engine.GoValueToJsValue(reflect.ValueOf(err))
+	}
+	jsErr.ToObject().SetProperty("cause", cause)
+
+	return jsErr
+}
+
 var (
-	typeOfValue    = reflect.TypeOf(new(Value))
-	typeOfObject   = reflect.TypeOf(new(Object))
-	typeOfArray    = reflect.TypeOf(new(Array))
-	typeOfRegExp   = reflect.TypeOf(new(RegExp))
-	typeOfFunction = reflect.TypeOf(new(Function))
+	typeOfError     = reflect.TypeOf((*error)(nil)).Elem()
+	typeOfTime      = reflect.TypeOf(time.Time{})
+	typeOfLiveMap   = reflect.TypeOf(LiveMap{})
+	typeOfLiveSlice = reflect.TypeOf(LiveSlice{})
+	typeOfValue     = reflect.TypeOf(new(Value))
+	typeOfObject    = reflect.TypeOf(new(Object))
+	typeOfArray     = reflect.TypeOf(new(Array))
+	typeOfRegExp    = reflect.TypeOf(new(RegExp))
+	typeOfFunction  = reflect.TypeOf(new(Function))
 )
 
+// SetJsValueToGo sets a Go value to a JS value converted to its type, the
+// conversion errors are ignored.
 func (engine *Engine) SetJsValueToGo(field reflect.Value, jsvalue *Value) {
+	engine.SetJsValueToGoE(field, jsvalue)
+}
+
+// SetJsValueToGoE is like SetJsValueToGo, but returns the errors of the
+// converters registered by RegisterConverter and of the FromJS methods.
+func (engine *Engine) SetJsValueToGoE(field reflect.Value, jsvalue *Value) error {
+	if converted, err := engine.convertFromJs(field, jsvalue); converted {
+		return err
+	}
+
 	goType := field.Type()
 	switch goType.Kind() {
 	case reflect.Bool:
@@ -381,6 +960,10 @@ func (engine *Engine) SetJsValueToGo(field reflect.Value, jsvalue *Value) {
 	case reflect.Float32, reflect.Float64:
 		field.SetFloat(jsvalue.ToNumber())
 	case reflect.Slice:
+		if view := liveViewOf(jsvalue); view != nil && view.value.Type().Elem() == goType {
+			field.Set(view.slice())
+			break
+		}
 		jsArray := jsvalue.ToArray()
 		jsArrayLen := jsArray.Length()
 		field.Set(reflect.MakeSlice(goType, jsArrayLen, jsArrayLen))
@@ -389,13 +972,19 @@ func (engine *Engine) SetJsValueToGo(field reflect.Value, jsvalue *Value) {
 		jsArray := jsvalue.ToArray()
 		jsArrayLen := jsArray.Length()
 		for i := 0; i < jsArrayLen; i++ {
-			engine.SetJsValueToGo(field.Index(i), jsArray.GetElement(i))
+			if err := engine.SetJsValueToGoE(field.Index(i), jsArray.GetElement(i)); err != nil {
+				return err
+			}
 		}
 	case reflect.Map:
 		if jsvalue.IsUndefined() || jsvalue.IsBoolean() || jsvalue.IsNumber() || jsvalue.IsString() {
 			// GetPropertyNames() causes SIGSEGV.
 			break
 		}
+		if view := liveViewOf(jsvalue); view != nil && view.value.Type() == goType {
+			field.Set(view.value)
+			break
+		}
 		jsObject := jsvalue.ToObject()
 		jsObjectKeys := jsObject.GetPropertyNames()
 		jsObjectKeysLen := jsObjectKeys.Length()
@@ -404,7 +993,9 @@ func (engine *Engine) SetJsValueToGo(field reflect.Value, jsvalue *Value) {
 		for i := 0; i < jsObjectKeysLen; i++ {
 			mapKey := jsObjectKeys.GetElement(i).ToString()
 			mapValue := reflect.Indirect(reflect.New(itemType))
-			engine.SetJsValueToGo(mapValue, jsObject.GetProperty(mapKey))
+			if err := engine.SetJsValueToGoE(mapValue, jsObject.GetProperty(mapKey)); err != nil {
+				return err
+			}
 			field.SetMapIndex(reflect.ValueOf(mapKey), mapValue)
 		}
 	case reflect.Interface:
@@ -416,10 +1007,40 @@ func (engine *Engine) SetJsValueToGo(field reflect.Value, jsvalue *Value) {
 			for i := 0; i < len(args); i++ {
 				jsargs[i] = engine.GoValueToJsValue(args[i])
 			}
-			jsresult := function.Call(jsargs...)
-
 			outNum := goType.NumOut()
 
+			// A trailing error returns the thrown exception.
+			if outNum > 0 && goType.Out(outNum-1) == typeOfError {
+				results := make([]reflect.Value, outNum)
+				for i := 0; i < outNum; i++ {
+					results[i] = reflect.Zero(goType.Out(i))
+				}
+
+				jsresult, err := engine.tryRunException(func() *Value {
+					return function.Call(jsargs...)
+				})
+				if err != nil {
+					results[outNum-1] = reflect.ValueOf(&err).Elem()
+					return results
+				}
+
+				switch {
+				case outNum == 2:
+					results[0] = reflect.Indirect(reflect.New(goType.Out(0)))
+					engine.SetJsValueToGo(results[0], jsresult)
+				case outNum > 2:
+					jsresultArray := jsresult.ToArray()
+					for i := 0; i < outNum-1; i++ {
+						results[i] = reflect.Indirect(reflect.New(goType.Out(i)))
+						engine.SetJsValueToGo(results[i], jsresultArray.GetElement(i))
+					}
+				}
+
+				return results
+			}
+
+			jsresult := function.Call(jsargs...)
+
 			if outNum == 1 {
 				var result = reflect.Indirect(reflect.New(goType.Out(0)))
 				engine.SetJsValueToGo(result, jsresult)
@@ -436,6 +1057,19 @@ func (engine *Engine) SetJsValueToGo(field reflect.Value, jsvalue *Value) {
 
 			return results
 		}))
+	case reflect.Ptr, reflect.Struct:
+		// Objects of bound types, and of their JS subclasses, share the Go value.
+		if dyObj := GetDynamicObject(jsvalue); dyObj != nil {
+			switch target := dyObj.Target; {
+			case target.Type() == goType:
+				field.Set(target)
+				return nil
+			case target.Kind() == reflect.Ptr && target.Type().Elem() == goType:
+				field.Set(target.Elem())
+				return nil
+			}
+		}
+		fallthrough
 	default:
 		switch goType {
 		case typeOfValue:
@@ -450,4 +1084,6 @@ func (engine *Engine) SetJsValueToGo(field reflect.Value, jsvalue *Value) {
 			field.Set(reflect.ValueOf(jsvalue.ToFunction()))
 		}
 	}
+
+	return nil
 }
@@ -3,6 +3,8 @@ package v8
 import "reflect"
 import "testing"
 import "runtime"
+import "errors"
+import "strings"
 
 func TestBindVariadic(t *testing.T) {
 	template := engine.NewObjectTemplate()
@@ -214,3 +216,401 @@ func TestBindInvalidMapArgument(t *testing.T) {
 		}
 	}
 }
+
+func TestBindErrorReturn(t *testing.T) {
+	template := engine.NewObjectTemplate()
+
+	template.Bind("Div", func(a, b int) (int, error) {
+		if b == 0 {
+			return 0, errors.New("division by zero")
+		}
+		return a / b, nil
+	})
+
+	template.Bind("Check", func(ok bool) error {
+		if !ok {
+			return errors.New("not ok")
+		}
+		return nil
+	})
+
+	engine.NewContext(template).Scope(func(cs ContextScope) {
+		value, err := cs.EvalE(`
+		if (Div(6, 3) !== 2) {
+			throw "value should be 2 not " + JSON.stringify(Div(6, 3));
+		}
+		if (Check(true) !== undefined) {
+			throw "value should be undefined";
+		}
+		var result;
+		try {
+			Div(1, 0);
+		} catch(e) {
+			result = e instanceof Error && e.message == "division by zero" && e.cause == "division by zero";
+		}
+		try {
+			Check(false);
+			result = false;
+		} catch(e) {
+			result = result && e.message == "not ok";
+		}
+		result;
+		`)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !value.IsTrue() {
+			t.Fatal("expected the errors to be thrown")
+		}
+	})
+}
+
+func TestBindErrorFunc(t *testing.T) {
+	type Callbacks struct {
+		Parse func(s string) (int, error)
+		Check func() error
+	}
+
+	var callbacks Callbacks
+
+	template := engine.NewObjectTemplate()
+	template.Bind("Register", func(parse, check *Value) {
+		engine.SetJsValueToGo(reflect.ValueOf(&callbacks.Parse).Elem(), parse)
+		engine.SetJsValueToGo(reflect.ValueOf(&callbacks.Check).Elem(), check)
+	})
+
+	engine.NewContext(template).Scope(func(cs ContextScope) {
+		cs.Eval(`
+		Register(function(s) {
+			var n = parseInt(s);
+			if (isNaN(n)) throw new TypeError("not a number: " + s);
+			return n;
+		}, function() {
+			throw new RangeError("check failed");
+		});
+		`)
+
+		n, err := callbacks.Parse("42")
+		if err != nil || n != 42 {
+			t.Fatalf("unexpected result %d %v", n, err)
+		}
+
+		n, err = callbacks.Parse("x")
+		if err == nil || n != 0 {
+			t.Fatalf("unexpected result %d %v", n, err)
+		}
+
+		exc, ok := err.(*Exception)
+		if !ok || exc.Name() != "TypeError" || !exc.Value.IsNativeError() {
+			t.Fatalf("unexpected error %#v", err)
+		}
+
+		if err := callbacks.Check(); err == nil {
+			t.Fatal("expected error")
+		} else if exc, ok := err.(*Exception); !ok || exc.Name() != "RangeError" {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	})
+}
+
+func TestBindStrict(t *testing.T) {
+	engine.SetStrictBinding(true)
+	defer engine.SetStrictBinding(false)
+
+	template := engine.NewObjectTemplate()
+	template.Bind("Add", func(a, b int) int {
+		return a + b
+	})
+	template.Bind("Join", func(sep string, items ...string) string {
+		result := ""
+		for i, item := range items {
+			if i > 0 {
+				result += sep
+			}
+			result += item
+		}
+		return result
+	})
+
+	engine.NewContext(template).Scope(func(cs ContextScope) {
+		if value, err := cs.EvalE(`Add(1, 2)`); err != nil || value.ToInteger() != 3 {
+			t.Fatalf("unexpected result %v %v", value, err)
+		}
+
+		if value, err := cs.EvalE(`Join(",", "a", "b")`); err != nil || value.ToString() != "a,b" {
+			t.Fatalf("unexpected result %v %v", value, err)
+		}
+
+		for code, message := range map[string]string{
+			`Add(1)`:            "Add: missing argument 2 of type int",
+			`Add(1, 2, 3)`:      "Add: unexpected argument 3, expects 2 arguments",
+			`Add(1, "2")`:       "Add: argument 2 should be int, not string",
+			`Join()`:            "Join: missing argument 1 of type string",
+			`Join(",", "a", 1)`: "Join: argument 3 should be string, not number",
+			`Add(null, 1)`:      "Add: argument 1 should be int, not null",
+		} {
+			value := cs.Eval(`
+			var result;
+			try {
+				` + code + `;
+			} catch(e) {
+				result = e instanceof TypeError ? e.message : "not a TypeError";
+			}
+			result;
+			`)
+			if value.ToString() != message {
+				t.Fatalf("%s: message should be %q not %q", code, message, value.ToString())
+			}
+		}
+	})
+}
+
+type BindingEnumTest struct {
+	Name     string
+	Count    int    `js-field:"count"`
+	Password string `js-field:",hidden"`
+	Secret   string `js-field:"secret,hidden"`
+	private  int
+}
+
+func (b *BindingEnumTest) Hello() string {
+	return "hello " + b.Name
+}
+
+func TestBindEnumerator(t *testing.T) {
+	template := engine.NewObjectTemplate()
+	template.Bind("BindingEnumTest", BindingEnumTest{})
+	template.Bind("Test", func() *BindingEnumTest {
+		return &BindingEnumTest{Name: "go", Count: 2, Password: "pass", Secret: "secret"}
+	})
+
+	engine.NewContext(template).Scope(func(cs ContextScope) {
+		value, err := cs.EvalE(`
+		var obj = Test();
+		obj.dynamic = true;
+
+		var forIn = [];
+		for (var k in obj) forIn.push(k);
+
+		if (obj.Hello() != "hello go" || obj.Password != "pass" || obj.secret != "secret") {
+			throw "hidden members should be accessible";
+		}
+		if (!("Password" in obj) || "Missing" in obj) {
+			throw "unexpected in operator result";
+		}
+
+		JSON.stringify([Object.keys(obj), forIn, obj]);
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := `[["Name","count","dynamic"],["Name","count","dynamic"],{"Name":"go","count":2,"dynamic":true}]`
+		if value.ToString() != expected {
+			t.Fatalf("value should be %s not %s", expected, value.ToString())
+		}
+
+		obj := cs.Eval(`obj`)
+		if json := string(ToJSON(obj)); json != `{"Name":"go","count":2,"dynamic":true}` {
+			t.Fatalf("unexpected JSON %s", json)
+		}
+	})
+}
+
+type BindingPoint struct {
+	X, Y float64
+}
+
+func NewBindingPoint(x, y float64) (*BindingPoint, error) {
+	if x < 0 || y < 0 {
+		return nil, errors.New("negative coordinate")
+	}
+	return &BindingPoint{x, y}, nil
+}
+
+func (p *BindingPoint) Sum() float64 {
+	return p.X + p.Y
+}
+
+func TestBindClass(t *testing.T) {
+	template := engine.NewObjectTemplate()
+	if err := template.BindClass("Point", NewBindingPoint); err != nil {
+		t.Fatal(err)
+	}
+	if err := template.BindClass("Invalid", func() int { return 0 }); err == nil {
+		t.Fatal("factory should return a struct pointer")
+	}
+
+	engine.NewContext(template).Scope(func(cs ContextScope) {
+		value, err := cs.EvalE(`
+		var p = new Point(1, 2);
+		[p.X, p.Y, p.Sum(), p instanceof Point].join();
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value.ToString() != "1,2,3,true" {
+			t.Fatalf(`value should be "1,2,3,true" not %q`, value.ToString())
+		}
+
+		_, err = cs.EvalE(`new Point(-1, 2)`)
+		if err == nil || !strings.Contains(err.Error(), "negative coordinate") {
+			t.Fatalf("factory error should be thrown, not %v", err)
+		}
+	})
+}
+
+type BindingMethodTest struct {
+	Name string
+}
+
+func (b *BindingMethodTest) Hello() string {
+	return "hello " + b.Name
+}
+
+func TestBindMethodIdentity(t *testing.T) {
+	template := engine.NewObjectTemplate()
+	template.Bind("BindingMethodTest", BindingMethodTest{})
+	template.Bind("NewBindingMethodTest", func() *BindingMethodTest {
+		return &BindingMethodTest{Name: "go"}
+	})
+
+	engine.NewContext(template).Scope(func(cs ContextScope) {
+		value, err := cs.EvalE(`
+		var a = NewBindingMethodTest(), b = new BindingMethodTest();
+		if (a.Hello !== a.Hello || a.Hello !== b.Hello || NewBindingMethodTest !== NewBindingMethodTest) {
+			throw "functions should be cached";
+		}
+		if (a.hasOwnProperty("Hello") || Object.keys(a).indexOf("Hello") != -1) {
+			throw "methods should be on the prototype";
+		}
+
+		var message;
+		try {
+			a.Hello.call({});
+		} catch(e) {
+			message = e instanceof TypeError ? e.message : "not a TypeError";
+		}
+		[a.Hello(), message].join();
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "hello go,BindingMethodTest.Hello: illegal invocation"
+		if value.ToString() != expected {
+			t.Fatalf("value should be %q not %q", expected, value.ToString())
+		}
+	})
+}
+
+type BindingShape struct {
+	Width, Height float64
+}
+
+func (s *BindingShape) Area() float64 {
+	return s.Width * s.Height
+}
+
+func TestBindSubclass(t *testing.T) {
+	var shape *BindingShape
+	var subclass string
+
+	template := engine.NewObjectTemplate()
+	template.Bind("Shape", BindingShape{})
+	template.Bind("Inspect", func(value *Value, s *BindingShape) {
+		shape = s
+		subclass = GetDynamicObject(value).Subclass
+	})
+
+	engine.NewContext(template).Scope(func(cs ContextScope) {
+		value, err := cs.EvalE(`
+		class Square extends Shape {
+			constructor(size) {
+				super();
+				this.Width = this.Height = size;
+				this.label = "square";
+			}
+			Area() { return "area " + super.Area(); }
+			get Size() { return this.Width; }
+			set Size(size) { this.Width = this.Height = size; }
+		}
+
+		var square = new Square(2);
+		square.Size = 3;
+		if (!(square instanceof Shape) || Object.getPrototypeOf(square) !== Square.prototype) {
+			throw "unexpected prototype chain";
+		}
+		Inspect(square, square);
+
+		var message;
+		try {
+			Shape();
+		} catch(e) {
+			message = e instanceof TypeError ? e.message : "not a TypeError";
+		}
+		[square.Area(), square.Size, square.label, message].join();
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "area 9,3,square,Shape: class constructor cannot be invoked without 'new'"
+		if value.ToString() != expected {
+			t.Fatalf("value should be %q not %q", expected, value.ToString())
+		}
+	})
+
+	if shape == nil || shape.Width != 3 || subclass != "Square" {
+		t.Fatalf("Go should get the subclass instance, not %v %q", shape, subclass)
+	}
+}
+
+type BindingBase struct {
+	ID   int
+	Note string
+}
+
+type BindingInner struct {
+	Count int
+}
+
+type BindingOuter struct {
+	*BindingBase
+	Note  string
+	Inner BindingInner
+	Any   interface{}
+}
+
+func TestBindEmbedded(t *testing.T) {
+	outer := &BindingOuter{Any: &BindingInner{Count: 5}}
+
+	template := engine.NewObjectTemplate()
+	template.Bind("GetOuter", func() *BindingOuter {
+		return outer
+	})
+
+	engine.NewContext(template).Scope(func(cs ContextScope) {
+		value, err := cs.EvalE(`
+		var outer = GetOuter();
+		var before = outer.ID;
+		outer.ID = 7;
+		outer.Note = "outer";
+		outer.Inner.Count++;
+		JSON.stringify([before, Object.keys(outer), outer.Any.Count, outer.BindingBase.ID]);
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := `[0,["ID","Note","Inner","Any"],5,7]`
+		if value.ToString() != expected {
+			t.Fatalf("value should be %s not %s", expected, value.ToString())
+		}
+	})
+
+	if outer.BindingBase == nil || outer.ID != 7 || outer.BindingBase.Note != "" || outer.Note != "outer" || outer.Inner.Count != 1 {
+		t.Fatalf("fields should be set through JS, not %+v", outer)
+	}
+}
@@ -7,8 +7,7 @@ package v8
 import "C"
 import "unsafe"
 import "runtime"
-
-//import "reflect"
+import "reflect"
 
 // A sandboxed execution context with its own set of built-in objects
 // and functions.
@@ -75,7 +74,30 @@ func go_escapable_scope_callback(c unsafe.Pointer, callback unsafe.Pointer) {
 }
 
 func (c *Context) Scope(callback func(ContextScope)) {
-	C.V8_Context_Scope(c.self, unsafe.Pointer(c), unsafe.Pointer(&callback))
+	var p *scopePanic
+	scope := func(cs ContextScope) {
+		defer recoverScopePanic(&p)
+		callback(cs)
+	}
+	C.V8_Context_Scope(c.self, unsafe.Pointer(c), unsafe.Pointer(&scope))
+	p.repanic()
+}
+
+// Panics when the engine isn't in a context scope. The C++ APIs which need
+// the current context return NULL without one, so the panic is raised in Go
+// instead of unwinding the C++ frames.
+func (e *Engine) checkContextScope() {
+	if C.V8_Engine_InContextScope(e.self) == 0 {
+		panic("Please call this API in a context scope")
+	}
+}
+
+// Like newValue for the C++ APIs which need the current context.
+func (e *Engine) newScopedValue(self unsafe.Pointer) *Value {
+	if self == nil {
+		e.checkContextScope()
+	}
+	return newValue(e, self)
 }
 
 func (c *Context) GetEngine() *Engine {
@@ -83,7 +105,13 @@ func (c *Context) GetEngine() *Engine {
 }
 
 func (c *Context) EscapableScope(callback func(EscapableScope)) {
-	C.V8_Escapable_Scope(c.self, unsafe.Pointer(c), unsafe.Pointer(&callback))
+	var p *scopePanic
+	scope := func(es EscapableScope) {
+		defer recoverScopePanic(&p)
+		callback(es)
+	}
+	C.V8_Escapable_Scope(c.self, unsafe.Pointer(c), unsafe.Pointer(&scope))
+	p.repanic()
 }
 
 func (c *Context) SetSecurityToken(value *Value) {
@@ -123,33 +151,6 @@ func go_try_catch_callback(callback unsafe.Pointer) {
 	(*(*func())(callback))()
 }
 
-func escape(s string) string {
-	output := ""
-	for _, r := range s {
-		switch r {
-		case '"':
-			output += "\\\""
-		case '\\':
-			output += "\\\\"
-		case '/':
-			output += "\\/"
-		case '\n':
-			output += "\\n"
-		case '\r':
-			output += "\\r"
-		case '\t':
-			output += "\\t"
-		case '\b':
-			output += "\\b"
-		case '\f':
-			output += "\\f"
-		default:
-			output += string(r)
-		}
-	}
-	return output
-}
-
 func (es EscapableScope) Escape(escontext *Context) *Context {
 	self := C.V8_Context_Escape(es.context.self, escontext.self)
 	if self == nil {
@@ -177,34 +178,143 @@ func (es EscapableScope) EscapeValue(value *Value) *Value {
 	return newValue(es.GetEngine(), self)
 }
 
+// ThrowException throws an Error with the message err. Like the other
+// throw methods, it must be called in a callback called by JavaScript, the
+// exception is thrown when the callback returns.
 func (cs ContextScope) ThrowException(err string) {
-	cs.Eval(`throw "` + escape(err) + `"`)
-	//
-	// TODO: use Isolate::ThrowException() will make FunctionTemplate::GetFunction() returns NULL, why?
-	//
-	//errPtr := unsafe.Pointer((*reflect.StringHeader)(unsafe.Pointer(&err)).Data)
-	//C.V8_Context_ThrowException(cs.context.self, (*C.char)(errPtr), C.int(len(err)))
+	errPtr := unsafe.Pointer((*reflect.StringHeader)(unsafe.Pointer(&err)).Data)
+	C.V8_Context_ThrowException(cs.context.self, (*C.char)(errPtr), C.int(len(err)))
 }
 
+// ThrowException2 throws any value.
 func (cs ContextScope) ThrowException2(value *Value) {
 	C.V8_Context_ThrowException2(value.self)
 }
 
+// ErrorKind is the constructor of a JavaScript error.
+type ErrorKind int
+
+const (
+	GenericError ErrorKind = iota // Error
+	RangeError
+	ReferenceError
+	SyntaxError
+	TypeError
+)
+
+// NewErrorKind creates an error of the kind.
+func (e *Engine) NewErrorKind(kind ErrorKind, message string) *Value {
+	switch kind {
+	case RangeError:
+		return e.NewRangeError(message)
+	case ReferenceError:
+		return e.NewReferenceError(message)
+	case SyntaxError:
+		return e.NewSyntaxError(message)
+	case TypeError:
+		return e.NewTypeError(message)
+	}
+	return e.NewError(message)
+}
+
+// ThrowError throws an error of the kind, like a TypeError.
+func (cs ContextScope) ThrowError(kind ErrorKind, message string) {
+	cs.ThrowException2(cs.GetEngine().NewErrorKind(kind, message))
+}
+
 func (cs ContextScope) TryCatch(callback func()) *Message {
-	msg := C.V8_Context_TryCatch(cs.context.self, unsafe.Pointer(&callback))
+	var p *scopePanic
+	try := func() {
+		defer recoverScopePanic(&p)
+		callback()
+	}
+	msg := C.V8_Context_TryCatch(cs.context.self, unsafe.Pointer(&try))
+	p.repanic()
 	if msg == nil {
 		return nil
 	}
 	return (*Message)(msg)
 }
 
+// Exception is a JavaScript exception caught by TryCatchException. Value is
+// the thrown value, with its custom properties, Message is where it was
+// thrown. The name and the stack are captured when it is caught, so they can
+// be read out of the context scope.
 type Exception struct {
 	*Value
 	*Message
+
+	name  string
+	stack string
+}
+
+// Creates the exception of a caught value, in the context scope.
+func newException(value *Value, message *Message) *Exception {
+	e := &Exception{Value: value, Message: message}
+	e.name = e.property("name")
+	e.stack = e.property("stack")
+	return e
+}
+
+func (e *Exception) Error() string {
+	if e.Message != nil {
+		return e.Message.Message
+	}
+	if e.Value != nil {
+		return e.Value.ToString()
+	}
+	return ""
+}
+
+// Unwrap returns the message of the exception.
+func (e *Exception) Unwrap() error {
+	if e.Message == nil {
+		return nil
+	}
+	return e.Message
+}
+
+// Returns a property of the thrown value, or "" when the thrown value
+// isn't an object.
+func (e *Exception) property(name string) string {
+	if e.Value == nil || !e.Value.IsObject() {
+		return ""
+	}
+	value := e.Value.ToObject().GetProperty(name)
+	if value == nil || value.IsUndefined() {
+		return ""
+	}
+	return value.ToString()
+}
+
+// Name returns the name of the thrown error, like "TypeError".
+func (e *Exception) Name() string {
+	return e.name
+}
+
+// Stack returns the JavaScript stack of the thrown error.
+func (e *Exception) Stack() string {
+	return e.stack
+}
+
+// ReThrow throws the caught exception again, the thrown value is unchanged.
+// Like ThrowException, it must be called in a callback called by JavaScript.
+func (cs ContextScope) ReThrow(e *Exception) {
+	if e.Value != nil {
+		cs.ThrowException2(e.Value)
+	} else {
+		cs.ThrowException(e.Error())
+	}
 }
 
 func (cs ContextScope) TryCatchException(callback func()) *Exception {
-	e := C.V8_Context_TryCatchException(cs.context.self, unsafe.Pointer(&callback))
+	var p *scopePanic
+	try := func() {
+		defer recoverScopePanic(&p)
+		callback()
+	}
+	e := C.V8_Context_TryCatchException(cs.context.self, unsafe.Pointer(&try))
+	p.repanic()
 	if e == nil {
 		return nil
 	}
@@ -215,7 +325,7 @@ func (cs ContextScope) TryCatchException(callback func()) *Exception {
 		return nil
 	}
 
-	return &Exception{val, excep.Message}
+	return newException(val, excep.Message)
 }
 
 func (cs ContextScope) Global() *Object {
@@ -0,0 +1,107 @@
+package v8
+
+import "reflect"
+
+// ToJS is implemented by the Go types which convert themselves to JS values.
+type ToJS interface {
+	ToJS(engine *Engine) *Value
+}
+
+// FromJS is implemented by the Go types which set themselves from JS values,
+// usually by a pointer receiver.
+type FromJS interface {
+	FromJS(value *Value) error
+}
+
+// A custom conversion of a Go type.
+type converter struct {
+	toJS   func(value reflect.Value) *Value
+	fromJS func(value *Value, target reflect.Value) error
+}
+
+var (
+	typeOfToJS   = reflect.TypeOf((*ToJS)(nil)).Elem()
+	typeOfFromJS = reflect.TypeOf((*FromJS)(nil)).Elem()
+)
+
+// RegisterConverter registers a custom conversion of a Go type, which is
+// used by GoValueToJsValue and SetJsValueToGo, so by the bindings too,
+// before the built-in conversions and the ToJS and FromJS methods. toJS
+// converts a value of the type to JS, and fromJS sets the target, a
+// settable value of the type, to a JS value. Either of them can be nil to
+// keep the default conversion of that direction.
+func (engine *Engine) RegisterConverter(typ reflect.Type, toJS func(value reflect.Value) *Value, fromJS func(value *Value, target reflect.Value) error) {
+	if engine.converters == nil {
+		engine.converters = make(map[reflect.Type]converter)
+	}
+	engine.converters[typ] = converter{toJS, fromJS}
+}
+
+// Converts a Go value by a registered converter or its ToJS method, and
+// returns false when it has neither.
+func (engine *Engine) convertToJs(value reflect.Value) (*Value, bool) {
+	if !value.IsValid() {
+		return nil, false
+	}
+
+	if c, exists := engine.converters[value.Type()]; exists && c.toJS != nil {
+		return c.toJS(value), true
+	}
+
+	if !value.CanInterface() {
+		return nil, false
+	}
+
+	if value.Type().Implements(typeOfToJS) {
+		if value.Kind() == reflect.Ptr && value.IsNil() {
+			return engine.Null(), true
+		}
+		return value.Interface().(ToJS).ToJS(engine), true
+	}
+
+	if value.CanAddr() && value.Addr().Type().Implements(typeOfToJS) {
+		return value.Addr().Interface().(ToJS).ToJS(engine), true
+	}
+
+	return nil, false
+}
+
+// Sets a Go value by a registered converter or its FromJS method, and
+// returns false when it has neither.
+func (engine *Engine) convertFromJs(field reflect.Value, jsvalue *Value) (bool, error) {
+	goType := field.Type()
+
+	if c, exists := engine.converters[goType]; exists && c.fromJS != nil {
+		return true, c.fromJS(jsvalue, field)
+	}
+
+	if reflect.PtrTo(goType).Implements(typeOfFromJS) && field.CanAddr() {
+		return true, field.Addr().Interface().(FromJS).FromJS(jsvalue)
+	}
+
+	// A pointer is allocated for the value, null and undefined are nil.
+	if goType.Kind() == reflect.Ptr && goType.Implements(typeOfFromJS) {
+		if jsvalue.IsNull() || jsvalue.IsUndefined() {
+			field.Set(reflect.Zero(goType))
+			return true, nil
+		}
+		target := reflect.New(goType.Elem())
+		if err := target.Interface().(FromJS).FromJS(jsvalue); err != nil {
+			return true, err
+		}
+		field.Set(target)
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// Reports whether a Go type has a custom conversion from JS, the ones
+// convertFromJs makes.
+func (engine *Engine) hasFromJs(goType reflect.Type) bool {
+	if c, exists := engine.converters[goType]; exists && c.fromJS != nil {
+		return true
+	}
+	return reflect.PtrTo(goType).Implements(typeOfFromJS) ||
+		goType.Kind() == reflect.Ptr && goType.Implements(typeOfFromJS)
+}
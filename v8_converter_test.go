@@ -0,0 +1,67 @@
+package v8
+
+import "errors"
+import "reflect"
+import "strings"
+import "testing"
+import "time"
+
+type ConverterPoint struct {
+	X, Y int
+}
+
+func (p ConverterPoint) ToJS(engine *Engine) *Value {
+	return engine.NewString(strings.Repeat("x", p.X) + "," + strings.Repeat("y", p.Y))
+}
+
+func (p *ConverterPoint) FromJS(value *Value) error {
+	parts := strings.Split(value.ToString(), ",")
+	if len(parts) != 2 {
+		return errors.New("invalid point " + value.ToString())
+	}
+	p.X, p.Y = len(parts[0]), len(parts[1])
+	return nil
+}
+
+func TestRegisterConverter(t *testing.T) {
+	engine := NewEngine()
+
+	engine.RegisterConverter(reflect.TypeOf(time.Duration(0)), func(value reflect.Value) *Value {
+		return engine.NewString(value.Interface().(time.Duration).String())
+	}, func(value *Value, target reflect.Value) error {
+		d, err := time.ParseDuration(value.ToString())
+		if err != nil {
+			return err
+		}
+		target.SetInt(int64(d))
+		return nil
+	})
+
+	template := engine.NewObjectTemplate()
+	template.Bind("Double", func(d time.Duration) time.Duration {
+		return d * 2
+	})
+	template.Bind("Move", func(p ConverterPoint) ConverterPoint {
+		return ConverterPoint{p.X + 1, p.Y + 1}
+	})
+
+	engine.NewContext(template).Scope(func(cs ContextScope) {
+		value, err := cs.EvalE(`[Double("1m30s"), Move("x,yy")].join()`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value.ToString() != "3m0s,xx,yyy" {
+			t.Fatalf(`value should be "3m0s,xx,yyy" not %q`, value.ToString())
+		}
+
+		for code, message := range map[string]string{
+			`Double("soon")`: `time: invalid duration`,
+			`Move("xy")`:     `invalid point xy`,
+		} {
+			_, err := cs.EvalE(code)
+			if err == nil || !strings.Contains(err.Error(), message) {
+				t.Fatalf("%s: error should contain %q, not %v", code, message, err)
+			}
+		}
+	})
+}
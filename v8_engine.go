@@ -5,10 +5,12 @@ package v8
 #include "v8_wrap.h"
 #include <stdlib.h>
 */
-
 import "C"
 import (
+	"context"
+	"errors"
 	"runtime"
+	"time"
 	"unsafe"
 )
 
@@ -41,7 +43,16 @@ type Engine struct {
 	firstMessageListener *messageListener
 	lastMessageListener  *messageListener
 
-	bindTypes map[reflect.Type]bindTypeInfo
+	bindTypes  map[reflect.Type]bindTypeInfo
+	converters map[reflect.Type]converter
+
+	liveMapTemplate   *ObjectTemplate
+	liveSliceTemplate *ObjectTemplate
+
+	cpuProfileInterval         time.Duration
+	allocationSamplingInterval uint64
+	callbackPanicHook          func(p *CallbackPanic)
+	strictBinding              bool
 }
 
 // Init initialize the V8 platform.
@@ -49,9 +60,45 @@ func init() {
 	C.V8_Init()
 }
 
+// ErrHeapLimit is returned when a script was terminated because the engine
+// was about to run out of heap.
+var ErrHeapLimit = errors.New("v8: heap limit exceeded")
+
+// ErrTerminated is returned when a script was terminated because its
+// context.Context was cancelled or its deadline passed.
+var ErrTerminated = errors.New("v8: execution terminated")
+
+// EngineOptions holds the resource limits and the startup snapshot of a new
+// engine. Zero values keep the V8 defaults.
+type EngineOptions struct {
+	MaxYoungSpaceSize int    // Max semi space size in MB.
+	MaxOldSpaceSize   int    // Max old space size in MB.
+	CodeRangeSize     int    // Code range size in MB.
+	StackLimit        int    // Max stack size in bytes JavaScript can use.
+	Snapshot          []byte // Startup snapshot made by CreateSnapshot.
+}
+
 // NewEngine create a new V8 engine.
 func NewEngine() *Engine {
-	self := C.V8_NewEngine()
+	return NewEngineWithOptions(EngineOptions{})
+}
+
+// NewEngineWithOptions create a new V8 engine with the given resource limits.
+// A script which exhausts the heap is terminated and RunE returns ErrHeapLimit,
+// the engine is usable again by the next run, also after Run. A script which
+// exhausts the stack throws a RangeError.
+func NewEngineWithOptions(options EngineOptions) *Engine {
+	coptions := C.V8_EngineOptions{
+		max_young_space_size: C.int(options.MaxYoungSpaceSize),
+		max_old_space_size:   C.int(options.MaxOldSpaceSize),
+		code_range_size:      C.int(options.CodeRangeSize),
+		stack_limit:          C.int(options.StackLimit),
+	}
+	if len(options.Snapshot) > 0 {
+		coptions.snapshot_data = (*C.char)(unsafe.Pointer(&options.Snapshot[0]))
+		coptions.snapshot_size = C.int(len(options.Snapshot))
+	}
+	self := C.V8_NewEngineWithOptions(&coptions)
 
 	if self == nil {
 		return nil
@@ -75,9 +122,114 @@ func NewEngine() *Engine {
 	return engine
 }
 
-//export go_panic
-func go_panic(message *C.char) {
-	panic(C.GoString(message))
+// Reports and clears the heap limit state set by a terminated script.
+func (engine *Engine) takeHeapLimitReached() bool {
+	return C.V8_Engine_TakeHeapLimitReached(engine.self) == 1
+}
+
+// TerminateExecution forcefully terminates the current thread of JavaScript
+// execution. It can be called from any goroutine.
+func (engine *Engine) TerminateExecution() {
+	C.V8_Engine_TerminateExecution(engine.self)
+}
+
+// CancelTerminateExecution resumes execution capability of an engine whose
+// execution was terminated by TerminateExecution.
+func (engine *Engine) CancelTerminateExecution() {
+	C.V8_Engine_CancelTerminateExecution(engine.self)
+}
+
+// IsExecutionTerminating reports whether the engine is terminating JavaScript
+// execution and has not been resumed by CancelTerminateExecution.
+func (engine *Engine) IsExecutionTerminating() bool {
+	return C.V8_Engine_IsExecutionTerminating(engine.self) == 1
+}
+
+// Runs the callback and reports uncaught exceptions as *Message and heap
+// exhaustion as ErrHeapLimit.
+func (engine *Engine) tryRun(run func() *Value) (*Value, error) {
+	var (
+		result *Value
+		p      *scopePanic
+	)
+	callback := func() {
+		defer recoverScopePanic(&p)
+		result = run()
+	}
+	msg := C.V8_Context_TryCatch(engine.self, unsafe.Pointer(&callback))
+	p.repanic()
+
+	if engine.takeHeapLimitReached() {
+		return nil, ErrHeapLimit
+	}
+
+	if msg != nil {
+		return nil, (*Message)(msg)
+	}
+
+	return result, nil
+}
+
+// Like tryRun, but returns uncaught exceptions as *Exception.
+func (engine *Engine) tryRunException(run func() *Value) (*Value, error) {
+	var (
+		result *Value
+		p      *scopePanic
+	)
+	callback := func() {
+		defer recoverScopePanic(&p)
+		result = run()
+	}
+	e := C.V8_Context_TryCatchException(engine.self, unsafe.Pointer(&callback))
+	p.repanic()
+
+	if engine.takeHeapLimitReached() {
+		return nil, ErrHeapLimit
+	}
+
+	if e != nil {
+		excep := (*exception)(e)
+		return nil, newException(newValue(engine, excep.p), excep.Message)
+	}
+
+	return result, nil
+}
+
+// Like tryRun, but terminates the script when ctx is done and returns
+// ErrTerminated.
+func (engine *Engine) tryRunContext(ctx context.Context, run func() *Value) (*Value, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, ErrTerminated
+	}
+
+	var (
+		terminated = false
+		done       = make(chan struct{})
+		exited     = make(chan struct{})
+	)
+
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			terminated = true
+			engine.TerminateExecution()
+		case <-done:
+		}
+	}()
+
+	result, err := engine.tryRun(run)
+
+	close(done)
+	<-exited
+
+	if terminated {
+		// Also clears a termination which arrived after the script finished.
+		engine.CancelTerminateExecution()
+		return nil, ErrTerminated
+	}
+
+	return result, err
 }
 
 //export go_field_owner_weak_callback
@@ -143,6 +295,8 @@ func (engine *Engine) RemoveMessageListener(id int64) {
 
 //export go_message_callback
 func go_message_callback(engine, message unsafe.Pointer) {
+	defer (*Engine)(engine).recoverCallbackPanic(false)
+
 	for i := (*Engine)(engine).firstMessageListener; i != nil; i = i.Next {
 		i.Callback((*Message)(message))
 	}
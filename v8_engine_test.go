@@ -243,6 +243,81 @@ func TestThreadSafe6(t *testing.T) {
 	}
 }
 
+func TestEngineHeapLimit(t *testing.T) {
+	limited := NewEngineWithOptions(EngineOptions{
+		MaxOldSpaceSize: 16,
+	})
+
+	limited.NewContext(nil).Scope(func(cs ContextScope) {
+		script := limited.Compile([]byte(`
+		var list = [];
+		while (true) {
+			list.push(new Array(1024).join('x'));
+		}
+		`), nil)
+
+		if _, err := cs.RunE(script); err != ErrHeapLimit {
+			t.Fatalf("expected ErrHeapLimit, got %v", err)
+		}
+
+		// the engine must be usable after a terminated script
+		value, err := cs.RunE(limited.Compile([]byte(`1 + 2`), nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value.ToInt32() != 3 {
+			t.Fatalf("value should be 3 not %d", value.ToInt32())
+		}
+
+		// the termination is cancelled by the next run when Run hit the limit
+		cs.Run(script)
+
+		value, err = cs.RunE(limited.Compile([]byte(`1 + 2`), nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value.ToInt32() != 3 {
+			t.Fatalf("value should be 3 not %d", value.ToInt32())
+		}
+	})
+}
+
+func TestEngineStackLimit(t *testing.T) {
+	code := []byte(`
+	function depth() {
+		try {
+			return depth() + 1;
+		} catch(e) {
+			return 0;
+		}
+	}
+	depth();
+	`)
+
+	var defaultDepth, limitedDepth int32
+
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		defaultDepth = cs.Run(engine.Compile(code, nil)).ToInt32()
+	})
+
+	limited := NewEngineWithOptions(EngineOptions{
+		StackLimit: 64 * 1024,
+	})
+
+	limited.NewContext(nil).Scope(func(cs ContextScope) {
+		limitedDepth = cs.Run(limited.Compile(code, nil)).ToInt32()
+
+		_, err := cs.RunE(limited.Compile([]byte(`(function f() { return f(); })()`), nil))
+		if err == nil {
+			t.Fatal("expected stack overflow error")
+		}
+	})
+
+	if limitedDepth <= 0 || limitedDepth >= defaultDepth {
+		t.Fatalf("limited depth %d should be less than default depth %d", limitedDepth, defaultDepth)
+	}
+}
+
 func Benchmark_NewContext(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		engine.NewContext(nil)
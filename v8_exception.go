@@ -8,6 +8,7 @@ import "C"
 import "unsafe"
 import "strings"
 import "fmt"
+import "runtime/debug"
 
 type StackTraceOptions uint
 
@@ -148,5 +149,69 @@ func go_make_exception(value, message unsafe.Pointer) unsafe.Pointer {
 
 	go_exception := &exception{value, msg}
 
-	return go_exception.p
+	return unsafe.Pointer(go_exception)
+}
+
+// CallbackPanic is a Go panic recovered in a callback called by JavaScript.
+type CallbackPanic struct {
+	Value interface{} // The value passed to panic.
+	Stack []byte      // The Go stack trace of the panic.
+}
+
+func (p *CallbackPanic) Error() string {
+	return fmt.Sprintf("go panic: %v", p.Value)
+}
+
+// OnCallbackPanic sets a hook called with the panics recovered in the Go
+// callbacks of functions, accessors, interceptors and access checks. The
+// panic is thrown to JavaScript as an Error with the panic value in its
+// message and the Go stack trace in its goStack property, the panic of an
+// access check denies the access instead.
+func (engine *Engine) OnCallbackPanic(hook func(p *CallbackPanic)) {
+	engine.callbackPanicHook = hook
+}
+
+// Recovers a panic in a Go callback called by JavaScript, a panic must not
+// unwind the C++ frames. The panic is thrown to JavaScript when throw is
+// true. Must be deferred directly by the callback.
+func (engine *Engine) recoverCallbackPanic(throw bool) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	p := &CallbackPanic{r, debug.Stack()}
+
+	if engine == nil {
+		return
+	}
+
+	if engine.callbackPanicHook != nil {
+		engine.callbackPanicHook(p)
+	}
+
+	if throw {
+		err := engine.NewError(p.Error())
+		err.ToObject().SetProperty("goStack", engine.NewString(string(p.Stack)))
+		C.V8_Context_ThrowException2(err.self)
+	}
+}
+
+// A panic which is raised again once the C++ frames have returned.
+type scopePanic struct {
+	value interface{}
+}
+
+// Recovers a panic in a Go callback called by a Go to C++ call, like
+// Context.Scope. Must be deferred directly by the callback.
+func recoverScopePanic(p **scopePanic) {
+	if r := recover(); r != nil {
+		*p = &scopePanic{r}
+	}
+}
+
+func (p *scopePanic) repanic() {
+	if p != nil {
+		panic(p.value)
+	}
 }
@@ -2,6 +2,8 @@ package v8
 
 import "testing"
 import "runtime"
+import "strings"
+import "fmt"
 
 func TestMessageListener(t *testing.T) {
 	id1 := engine.AddMessageListener(func(message *Message) {
@@ -52,16 +54,43 @@ func TestThrowException(t *testing.T) {
 		`), nil)
 
 		value := cs.Run(script)
-		if !value.IsString() {
-			t.Fatalf("expected string")
-		} else if value.ToString() != "a \"nice\" error" {
-			t.Fatalf("value should be %q not %q", "a \"nice\" error", value.ToString())
+		if !value.IsNativeError() {
+			t.Fatalf("expected error")
+		} else if msg := value.ToObject().GetProperty("message").ToString(); msg != "a \"nice\" error" {
+			t.Fatalf("message should be %q not %q", "a \"nice\" error", msg)
 		}
 	})
 
 	runtime.GC()
 }
 
+func TestThrowError(t *testing.T) {
+	template := engine.NewObjectTemplate()
+	template.Bind("Call", func(kind int) {
+		engine.NewContext(nil).Scope(func(cs ContextScope) {
+			cs.ThrowError(ErrorKind(kind), "abcde")
+		})
+	})
+
+	engine.NewContext(template).Scope(func(cs ContextScope) {
+		for kind, name := range []string{"Error", "RangeError", "ReferenceError", "SyntaxError", "TypeError"} {
+			value := cs.Eval(fmt.Sprintf(`
+			var result;
+			try {
+				Call(%d);
+			} catch(e) {
+				result = e instanceof %s && e.name == %q && e.message == "abcde" && e.stack.indexOf("at ") > 0;
+			}
+			result;
+			`, kind, name, name))
+
+			if !value.IsTrue() {
+				t.Fatalf("expected %s", name)
+			}
+		}
+	})
+}
+
 func TestThrowException2(t *testing.T) {
 	template := engine.NewObjectTemplate()
 	template.Bind("Call", func() {
@@ -374,3 +403,213 @@ func TestTryCatchExceptionPrimitive(t *testing.T) {
 
 	runtime.GC()
 }
+
+func TestCallbackPanic(t *testing.T) {
+	var recovered *CallbackPanic
+	engine.OnCallbackPanic(func(p *CallbackPanic) {
+		recovered = p
+	})
+	defer engine.OnCallbackPanic(nil)
+
+	template := engine.NewObjectTemplate()
+	template.Bind("Call", func() {
+		panic("boom")
+	})
+
+	engine.NewContext(template).Scope(func(cs ContextScope) {
+		value := cs.Eval(`
+		var result;
+		try {
+			Call();
+		} catch(e) {
+			result = [e instanceof Error, e.message, e.goStack];
+		}
+		result;
+		`)
+
+		if value == nil || !value.IsArray() {
+			t.Fatal("expected the panic to be thrown")
+		}
+
+		result := value.ToArray()
+		if !result.GetElement(0).IsTrue() {
+			t.Fatal("expected an Error")
+		}
+		if msg := result.GetElement(1).ToString(); msg != "go panic: boom" {
+			t.Fatalf("unexpected message %q", msg)
+		}
+		if stack := result.GetElement(2).ToString(); !strings.Contains(stack, "TestCallbackPanic") {
+			t.Fatalf("unexpected Go stack %q", stack)
+		}
+	})
+
+	if recovered == nil || recovered.Value != "boom" || len(recovered.Stack) == 0 {
+		t.Fatalf("unexpected recovered panic %+v", recovered)
+	}
+
+	// The panic is thrown inside of the nested scope of the callback.
+	template.Bind("ScopeCall", func() {
+		engine.NewContext(nil).Scope(func(cs ContextScope) {
+			panic("scope boom")
+		})
+	})
+
+	engine.NewContext(template).Scope(func(cs ContextScope) {
+		_, err := cs.EvalE(`ScopeCall()`)
+		if err == nil || !strings.Contains(err.Error(), "go panic: scope boom") {
+			t.Fatalf("unexpected error %v", err)
+		}
+	})
+}
+
+func TestAccessCheckPanic(t *testing.T) {
+	engine := NewEngine()
+
+	var recovered *CallbackPanic
+	engine.OnCallbackPanic(func(p *CallbackPanic) {
+		recovered = p
+	})
+
+	template := engine.NewObjectTemplate()
+	template.SetAccessCheckCallbacks(
+		func(info AccessCheckCallbackInfo) bool {
+			panic("access boom")
+		},
+		func(info AccessCheckCallbackInfo) bool {
+			panic("access boom")
+		},
+		nil,
+	)
+
+	var global *Object
+	engine.NewContext(template).Scope(func(cs ContextScope) {
+		global = cs.Global()
+	})
+
+	// The global of a context with another security token is checked.
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		cs.Global().SetProperty("other", global.Value)
+		cs.EvalE(`other.x`)
+	})
+
+	if recovered == nil || recovered.Value != "access boom" || len(recovered.Stack) == 0 {
+		t.Fatalf("unexpected recovered panic %+v", recovered)
+	}
+}
+
+func TestScopePanic(t *testing.T) {
+	func() {
+		defer func() {
+			if r := recover(); r != "scope panic" {
+				t.Fatalf("unexpected panic %v", r)
+			}
+		}()
+
+		engine.NewContext(nil).Scope(func(cs ContextScope) {
+			panic("scope panic")
+		})
+	}()
+
+	// The engine is still usable after the panic.
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		if value := cs.Eval(`1 + 1`); value.ToInteger() != 2 {
+			t.Fatalf("unexpected result %v", value)
+		}
+	})
+}
+
+func TestNoScopePanic(t *testing.T) {
+	var object *Object
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		object = cs.Eval(`({a: 1})`).ToObject()
+	})
+
+	for name, call := range map[string]func(){
+		"GetProperty": func() { object.GetProperty("a") },
+		"NewFunction": func() { engine.NewFunction(func(FunctionCallbackInfo) {}, nil) },
+		"Serialize":   func() { engine.Serialize(object.Value) },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != "Please call this API in a context scope" {
+					t.Fatalf("%s: unexpected panic %v", name, r)
+				}
+			}()
+			call()
+		}()
+	}
+
+	// The engine is still usable after the panics.
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		if value := object.GetProperty("a"); value.ToInteger() != 1 {
+			t.Fatalf("unexpected result %v", value)
+		}
+	})
+}
+
+func TestExceptionReThrow(t *testing.T) {
+	var caught *Exception
+
+	template := engine.NewObjectTemplate()
+	template.Bind("Call", func(callback *Value) {
+		engine.NewContext(nil).Scope(func(cs ContextScope) {
+			caught = cs.TryCatchException(func() {
+				callback.ToFunction().Call()
+			})
+			if caught != nil {
+				cs.ReThrow(caught)
+			}
+		})
+	})
+
+	engine.NewContext(template).Scope(func(cs ContextScope) {
+		value := cs.Eval(`
+		function CustomError(message) {
+			this.name = "CustomError";
+			this.message = message;
+			this.stack = (new Error(message)).stack;
+			this.code = 42;
+		}
+
+		var thrown = new CustomError("custom");
+		var result;
+		try {
+			Call(function() { throw thrown; });
+		} catch(e) {
+			result = e === thrown;
+		}
+		result;
+		`)
+
+		if !value.IsTrue() {
+			t.Fatal("the rethrown value should be unchanged")
+		}
+
+		if caught == nil {
+			t.Fatal("expected an exception")
+		}
+
+		// The custom properties are read from the value in a context scope.
+		if code := caught.Value.ToObject().GetProperty("code"); code.ToInteger() != 42 {
+			t.Fatalf("unexpected code %v", code)
+		}
+	})
+
+	var err error = caught
+	if !strings.HasPrefix(err.Error(), "Uncaught ") {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+
+	if msg, ok := caught.Unwrap().(*Message); !ok || msg != caught.Message {
+		t.Fatalf("unexpected unwrapped error %#v", caught.Unwrap())
+	}
+
+	// The name and the stack are captured out of the context scope.
+	if caught.Name() != "CustomError" {
+		t.Fatalf("unexpected name %q", caught.Name())
+	}
+
+	if !strings.Contains(caught.Stack(), "Error: custom") {
+		t.Fatalf("unexpected stack %q", caught.Stack())
+	}
+}
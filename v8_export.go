@@ -0,0 +1,125 @@
+package v8
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// ExportedFunc is the Go wrapper of a JS function exported by Export. The
+// arguments are converted by GoValueToJsValue, and the result by Export.
+// A thrown exception is returned as *Exception.
+type ExportedFunc func(args ...interface{}) (interface{}, error)
+
+// Export converts the value to a plain Go value, or returns nil when it
+// can't be converted, see ExportE.
+func (v *Value) Export() interface{} {
+	value, _ := v.ExportE()
+	return value
+}
+
+// ExportE converts the value to a plain Go value:
+//
+//	undefined and null    nil
+//	boolean               bool
+//	integral number       int64
+//	other number          float64
+//	string                string
+//	date                  time.Time
+//	array                 []interface{}
+//	function              ExportedFunc
+//	bound Go object       the Go value, usually a pointer
+//	LiveMap or LiveSlice  the Go map or slice
+//	other object          map[string]interface{} of the own properties
+//
+// The other values, like symbols, are returned as *Value. An error is
+// returned when an object contains itself.
+func (v *Value) ExportE() (interface{}, error) {
+	return v.export("", nil)
+}
+
+// Exports a value, ancestors are the objects which contain it.
+func (v *Value) export(path string, ancestors []*Value) (interface{}, error) {
+	switch {
+	case v.IsUndefined() || v.IsNull():
+		return nil, nil
+	case v.IsBoolean():
+		return v.ToBoolean(), nil
+	case v.IsNumber():
+		n := v.ToNumber()
+		if n == math.Trunc(n) && n >= math.MinInt64 && n < math.MaxInt64 {
+			return int64(n), nil
+		}
+		return n, nil
+	case v.IsString():
+		return v.ToString(), nil
+	case v.IsDate():
+		return v.ToTime(), nil
+	case v.IsFunction():
+		return v.exportFunction(), nil
+	case !v.IsObject():
+		return v, nil
+	}
+
+	if dyObj := GetDynamicObject(v); dyObj != nil {
+		return dyObj.Target.Interface(), nil
+	}
+
+	if view := liveViewOf(v); view != nil {
+		if view.value.Kind() == reflect.Map {
+			return view.value.Interface(), nil
+		}
+		return view.slice().Interface(), nil
+	}
+
+	ancestors, err := withAncestor(ancestors, v, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.IsArray() {
+		array := v.ToArray()
+		elements := make([]interface{}, array.Length())
+		for i := range elements {
+			element, err := array.GetElement(i).export(fmt.Sprintf("%s[%d]", path, i), ancestors)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = element
+		}
+		return elements, nil
+	}
+
+	object := v.ToObject()
+	names := object.GetOwnPropertyNames()
+	properties := make(map[string]interface{}, names.Length())
+	for i := 0; i < names.Length(); i++ {
+		name := names.GetElement(i).ToString()
+		property, err := object.GetProperty(name).export(path+"."+name, ancestors)
+		if err != nil {
+			return nil, err
+		}
+		properties[name] = property
+	}
+	return properties, nil
+}
+
+func (v *Value) exportFunction() ExportedFunc {
+	engine := v.engine
+	function := v.ToFunction()
+
+	return func(args ...interface{}) (interface{}, error) {
+		jsargs := make([]*Value, len(args))
+		for i, arg := range args {
+			jsargs[i] = engine.GoValueToJsValue(reflect.ValueOf(arg))
+		}
+
+		result, err := engine.tryRunException(func() *Value {
+			return function.Call(jsargs...)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return result.ExportE()
+	}
+}
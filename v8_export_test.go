@@ -0,0 +1,58 @@
+package v8
+
+import "reflect"
+import "strings"
+import "testing"
+import "time"
+
+type ExportTest struct {
+	Name string
+}
+
+func TestExport(t *testing.T) {
+	goValue := &ExportTest{"go"}
+
+	template := engine.NewObjectTemplate()
+	template.Bind("GetExportTest", func() *ExportTest {
+		return goValue
+	})
+
+	engine.NewContext(template).Scope(func(cs ContextScope) {
+		value := cs.Eval(`({
+			n: 1, f: 1.5, s: "s", b: true, u: undefined, z: null,
+			a: [1, [2]], d: new Date(0), o: {x: {}}, g: GetExportTest()
+		})`).Export()
+
+		expected := map[string]interface{}{
+			"n": int64(1), "f": 1.5, "s": "s", "b": true, "u": nil, "z": nil,
+			"a": []interface{}{int64(1), []interface{}{int64(2)}},
+			"d": time.Unix(0, 0),
+			"o": map[string]interface{}{"x": map[string]interface{}{}},
+			"g": goValue,
+		}
+		exported := value.(map[string]interface{})
+		if !exported["d"].(time.Time).Equal(expected["d"].(time.Time)) {
+			t.Fatalf("date should be %v not %v", expected["d"], exported["d"])
+		}
+		exported["d"] = expected["d"]
+		if !reflect.DeepEqual(exported, expected) {
+			t.Fatalf("value should be %#v not %#v", expected, exported)
+		}
+		if exported["g"].(*ExportTest) != goValue {
+			t.Fatal("bound object should be the original Go pointer")
+		}
+
+		add := cs.Eval(`(function(a, b) { if (b === undefined) throw new Error("no b"); return a + b })`).Export().(ExportedFunc)
+		if result, err := add(1, 2); err != nil || result != int64(3) {
+			t.Fatalf("result should be 3 not %v %v", result, err)
+		}
+		if _, err := add(1); err == nil || !strings.Contains(err.Error(), "no b") {
+			t.Fatalf("exception should be returned, not %v", err)
+		}
+
+		_, err := cs.Eval(`var c = {a: [{}]}; c.a[0].c = c; c`).ExportE()
+		if err == nil || err.Error() != "field .a[0].c: cyclic object" {
+			t.Fatalf("cycle should be an error, not %v", err)
+		}
+	})
+}
@@ -4,6 +4,7 @@ package v8
 #include "v8_wrap.h"
 */
 import "C"
+import "context"
 import "unsafe"
 import "reflect"
 
@@ -19,6 +20,8 @@ type FunctionCallback func(FunctionCallbackInfo)
 
 //export go_function_callback
 func go_function_callback(info, callback, context, data unsafe.Pointer) {
+	defer (*Context)(context).engine.recoverCallbackPanic(true)
+
 	callbackFunc := *(*func(FunctionCallbackInfo))(callback)
 	callbackFunc(FunctionCallbackInfo{
 		info,
@@ -33,7 +36,7 @@ func (e *Engine) NewFunction(callback FunctionCallback, data interface{}) *Funct
 	function.data = data
 	function.callback = callback
 
-	function.Object = newValue(e, C.V8_NewFunction(
+	function.Object = e.newScopedValue(C.V8_NewFunction(
 		e.self, unsafe.Pointer(&function.callback), unsafe.Pointer(&function.data),
 	)).ToObject()
 
@@ -47,18 +50,38 @@ func (f *Function) Call(args ...*Value) *Value {
 	for i, arg := range args {
 		argv[i] = arg.self
 	}
-	return newValue(f.engine, C.V8_Function_Call(
+	return f.engine.newScopedValue(C.V8_Function_Call(
 		f.self, C.int(len(args)),
 		unsafe.Pointer((*reflect.SliceHeader)(unsafe.Pointer(&argv)).Data),
 	))
 }
 
+// Calls the function like Call, with recv as this.
+func (f *Function) callWithReceiver(recv *Value, args ...*Value) *Value {
+	argv := make([]unsafe.Pointer, len(args))
+	for i, arg := range args {
+		argv[i] = arg.self
+	}
+	return f.engine.newScopedValue(C.V8_Function_CallWithReceiver(
+		f.self, recv.self, C.int(len(args)),
+		unsafe.Pointer((*reflect.SliceHeader)(unsafe.Pointer(&argv)).Data),
+	))
+}
+
+// CallContext calls the function like Call, but returns uncaught exceptions
+// as *Message and terminates the call when ctx is done.
+func (f *Function) CallContext(ctx context.Context, args ...*Value) (*Value, error) {
+	return f.engine.tryRunContext(ctx, func() *Value {
+		return f.Call(args...)
+	})
+}
+
 func (f *Function) NewInstance(args ...*Value) *Value {
 	argv := make([]unsafe.Pointer, len(args))
 	for i, arg := range args {
 		argv[i] = arg.self
 	}
-	return newValue(f.engine, C.V8_Function_NewInstance(
+	return f.engine.newScopedValue(C.V8_Function_NewInstance(
 		f.self, C.int(len(args)),
 		unsafe.Pointer((*reflect.SliceHeader)(unsafe.Pointer(&argv)).Data),
 	))
@@ -132,6 +155,13 @@ func (fc FunctionCallbackInfo) Callee() *Function {
 	return newValue(fc.context.engine, C.V8_FunctionCallbackInfo_Callee(fc.self)).ToFunction()
 }
 
+// NewTarget returns new.target, the constructor called by the new operator,
+// which is a subclass when the function is called by super(). It is
+// undefined when the function isn't called as a constructor.
+func (fc FunctionCallbackInfo) NewTarget() *Value {
+	return newValue(fc.context.engine, C.V8_FunctionCallbackInfo_NewTarget(fc.self))
+}
+
 func (fc FunctionCallbackInfo) This() *Object {
 	return newValue(fc.context.engine, C.V8_FunctionCallbackInfo_This(fc.self)).ToObject()
 }
@@ -1,6 +1,10 @@
 package v8
 
-import "testing"
+import (
+	"context"
+	"testing"
+	"time"
+)
 
 func TestNewFunction(t *testing.T) {
 	engine.NewContext(nil).Scope(func(cs ContextScope) {
@@ -78,3 +82,28 @@ func TestNewInstance(t *testing.T) {
 		}
 	})
 }
+
+func TestFunctionCallContext(t *testing.T) {
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		loop := cs.Eval(`(function(){ while(true) {} })`).ToFunction()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+		}()
+
+		if _, err := loop.CallContext(ctx); err != ErrTerminated {
+			t.Fatalf("expected ErrTerminated, got %v", err)
+		}
+
+		add := cs.Eval(`(function(a, b){ return a + b; })`).ToFunction()
+		value, err := add.CallContext(context.Background(), engine.NewInteger(1), engine.NewInteger(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value.ToInt32() != 3 {
+			t.Fatalf("value should be 3 not %d", value.ToInt32())
+		}
+	})
+}
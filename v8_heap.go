@@ -0,0 +1,69 @@
+package v8
+
+/*
+#include "v8_wrap.h"
+*/
+import "C"
+
+// HeapStatistics is a snapshot of the memory usage of an engine.
+// All sizes are in bytes.
+type HeapStatistics struct {
+	TotalHeapSize           uint64
+	TotalHeapSizeExecutable uint64
+	TotalPhysicalSize       uint64
+	TotalAvailableSize      uint64
+	UsedHeapSize            uint64
+	HeapSizeLimit           uint64
+	MallocedMemory          uint64
+	PeakMallocedMemory      uint64
+	ExternalMemory          int64 // Memory kept alive by JS objects but allocated outside the V8 heap.
+	Spaces                  []HeapSpaceStatistics
+}
+
+// HeapSpaceStatistics is the memory usage of one V8 heap space,
+// such as "new_space" or "old_space".
+type HeapSpaceStatistics struct {
+	Name          string
+	Size          uint64
+	UsedSize      uint64
+	AvailableSize uint64
+	PhysicalSize  uint64
+}
+
+// HeapStatistics returns the current heap statistics of the engine,
+// including a breakdown per heap space.
+func (engine *Engine) HeapStatistics() *HeapStatistics {
+	var cstats C.V8_HeapStatistics
+	C.V8_Engine_GetHeapStatistics(engine.self, &cstats)
+
+	stats := &HeapStatistics{
+		TotalHeapSize:           uint64(cstats.total_heap_size),
+		TotalHeapSizeExecutable: uint64(cstats.total_heap_size_executable),
+		TotalPhysicalSize:       uint64(cstats.total_physical_size),
+		TotalAvailableSize:      uint64(cstats.total_available_size),
+		UsedHeapSize:            uint64(cstats.used_heap_size),
+		HeapSizeLimit:           uint64(cstats.heap_size_limit),
+		MallocedMemory:          uint64(cstats.malloced_memory),
+		PeakMallocedMemory:      uint64(cstats.peak_malloced_memory),
+		ExternalMemory:          int64(cstats.external_memory),
+	}
+
+	num := int(C.V8_Engine_NumberOfHeapSpaces(engine.self))
+	stats.Spaces = make([]HeapSpaceStatistics, 0, num)
+
+	for i := 0; i < num; i++ {
+		var cspace C.V8_HeapSpaceStatistics
+		if C.V8_Engine_GetHeapSpaceStatistics(engine.self, &cspace, C.int(i)) == 0 {
+			continue
+		}
+		stats.Spaces = append(stats.Spaces, HeapSpaceStatistics{
+			Name:          C.GoString(cspace.space_name),
+			Size:          uint64(cspace.space_size),
+			UsedSize:      uint64(cspace.space_used_size),
+			AvailableSize: uint64(cspace.space_available_size),
+			PhysicalSize:  uint64(cspace.physical_space_size),
+		})
+	}
+
+	return stats
+}
@@ -0,0 +1,167 @@
+package v8
+
+/*
+#include "v8_wrap.h"
+*/
+import "C"
+import (
+	"io"
+	"math"
+	"unsafe"
+)
+
+// AllocationProfile is the call tree of the sampled allocations which
+// are still alive, recorded by the sampling heap profiler.
+type AllocationProfile struct {
+	Root *AllocationProfileNode
+
+	interval uint64
+	nodes    map[uint]*AllocationProfileNode
+}
+
+// AllocationProfileNode is a JS function in the call tree of an AllocationProfile.
+type AllocationProfileNode struct {
+	FunctionName string
+	ScriptName   string
+	ScriptID     int
+	Line         int
+	Column       int
+	Allocations  []Allocation // Sampled allocations done by this function itself.
+	Parent       *AllocationProfileNode
+	Children     []*AllocationProfileNode
+}
+
+// Allocation is the number of sampled objects of a size.
+type Allocation struct {
+	Size  uint64
+	Count uint
+}
+
+const (
+	defaultAllocationSamplingInterval   = 512 * 1024
+	defaultAllocationSamplingStackDepth = 64
+)
+
+// StartAllocationSampling starts sampling an allocation every interval
+// bytes on average, the default interval is 512KB. Starting sampling while
+// sampling is ignored.
+func (engine *Engine) StartAllocationSampling(interval int) {
+	if interval <= 0 {
+		interval = defaultAllocationSamplingInterval
+	}
+	if C.V8_Engine_StartSamplingHeapProfiler(engine.self, C.uint64_t(interval), defaultAllocationSamplingStackDepth) != 0 {
+		engine.allocationSamplingInterval = uint64(interval)
+	}
+}
+
+// StopAllocationSampling stops sampling and discards the sampled allocations.
+func (engine *Engine) StopAllocationSampling() {
+	C.V8_Engine_StopSamplingHeapProfiler(engine.self)
+}
+
+// AllocationProfile returns the sampled allocations since sampling started
+// which are still alive. It returns nil when sampling isn't started.
+func (engine *Engine) AllocationProfile() *AllocationProfile {
+	profile := &AllocationProfile{
+		interval: engine.allocationSamplingInterval,
+		nodes:    make(map[uint]*AllocationProfileNode),
+	}
+
+	if C.V8_Engine_GetAllocationProfile(engine.self, unsafe.Pointer(profile)) == 0 {
+		return nil
+	}
+
+	profile.nodes = nil
+	return profile
+}
+
+//export go_allocation_profile_node
+func go_allocation_profile_node(p unsafe.Pointer, parentId, id uint, functionName, scriptName *C.char, scriptId, line, column int) {
+	profile := (*AllocationProfile)(p)
+
+	node := &AllocationProfileNode{
+		FunctionName: C.GoString(functionName),
+		ScriptName:   C.GoString(scriptName),
+		ScriptID:     scriptId,
+		Line:         line,
+		Column:       column,
+	}
+
+	if parent, exists := profile.nodes[parentId]; exists {
+		node.Parent = parent
+		parent.Children = append(parent.Children, node)
+	} else {
+		profile.Root = node
+	}
+
+	profile.nodes[id] = node
+}
+
+//export go_allocation_profile_allocation
+func go_allocation_profile_allocation(p unsafe.Pointer, id uint, size uint64, count uint) {
+	profile := (*AllocationProfile)(p)
+
+	if node, exists := profile.nodes[id]; exists {
+		node.Allocations = append(node.Allocations, Allocation{size, count})
+	}
+}
+
+// Walk calls fn for each node of the call tree in depth-first order.
+func (p *AllocationProfile) Walk(fn func(node *AllocationProfileNode)) {
+	if p.Root != nil {
+		p.Root.walk(fn)
+	}
+}
+
+func (n *AllocationProfileNode) walk(fn func(node *AllocationProfileNode)) {
+	fn(n)
+	for _, child := range n.Children {
+		child.walk(fn)
+	}
+}
+
+// Returns a display name of the function like Chrome DevTools does.
+func (n *AllocationProfileNode) displayName() string {
+	if n.FunctionName == "" {
+		return "(anonymous)"
+	}
+	return n.FunctionName
+}
+
+// WritePprof writes the profile in the gzip compressed protocol buffer
+// format read by 'go tool pprof'. Like the Go heap profile, the sampled
+// counts are scaled to estimate all of the allocations.
+func (p *AllocationProfile) WritePprof(w io.Writer) error {
+	b := newPprofBuilder()
+	b.sampleTypes = [][2]string{{"inuse_objects", "count"}, {"inuse_space", "bytes"}}
+	b.periodType = [2]string{"space", "bytes"}
+	b.period = int64(p.interval)
+
+	p.Walk(func(node *AllocationProfileNode) {
+		if len(node.Allocations) == 0 || node == p.Root {
+			return
+		}
+
+		var stack []pprofLocation
+		for n := node; n != nil && n != p.Root; n = n.Parent {
+			stack = append(stack, pprofLocation{
+				function: pprofFunction{n.displayName(), n.ScriptName, n.Line},
+				line:     n.Line,
+			})
+		}
+
+		var objects, space float64
+		for _, a := range node.Allocations {
+			scale := 1.0
+			if p.interval > 0 && a.Size > 0 {
+				scale = 1 / (1 - math.Exp(-float64(a.Size)/float64(p.interval)))
+			}
+			objects += float64(a.Count) * scale
+			space += float64(a.Count) * float64(a.Size) * scale
+		}
+
+		b.addSample(stack, int64(objects), int64(space))
+	})
+
+	return b.write(w)
+}
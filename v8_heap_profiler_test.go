@@ -0,0 +1,66 @@
+package v8
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"testing"
+)
+
+func TestAllocationProfile(t *testing.T) {
+	if engine.AllocationProfile() != nil {
+		t.Fatal("no allocations should be sampled")
+	}
+
+	engine.StartAllocationSampling(1024)
+	defer engine.StopAllocationSampling()
+
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		script := engine.Compile([]byte(`
+		var allocationProfileTest = [];
+		function allocate() {
+			for (var i = 0; i < 10000; i++) allocationProfileTest.push({i: i});
+		}
+		allocate();
+		`), engine.NewScriptOrigin("allocate.js", 0, 0))
+		cs.Run(script)
+	})
+
+	profile := engine.AllocationProfile()
+	if profile == nil {
+		t.Fatal("profile == nil")
+	}
+
+	var sampled uint
+	profile.Walk(func(node *AllocationProfileNode) {
+		if node.FunctionName == "allocate" {
+			if node.ScriptName != "allocate.js" || node.Line != 3 {
+				t.Fatalf("unexpected node %+v", node)
+			}
+			for _, a := range node.Allocations {
+				sampled += a.Count
+			}
+		}
+	})
+
+	if sampled == 0 {
+		t.Fatal("no sampled allocations in allocate")
+	}
+
+	var buf bytes.Buffer
+	if err := profile.WritePprof(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte("allocate.js")) || !bytes.Contains(data, []byte("inuse_space")) {
+		t.Fatal("unexpected pprof profile")
+	}
+}
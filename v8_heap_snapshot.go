@@ -0,0 +1,259 @@
+package v8
+
+/*
+#include "v8_wrap.h"
+*/
+import "C"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"unsafe"
+)
+
+// A Go io.Writer used by the heap snapshot output stream.
+type heapSnapshotWriter struct {
+	w   io.Writer
+	err error
+}
+
+//export go_heap_snapshot_write
+func go_heap_snapshot_write(p unsafe.Pointer, data *C.char, size C.int) C.int {
+	writer := (*heapSnapshotWriter)(p)
+	if _, err := writer.w.Write(C.GoBytes(unsafe.Pointer(data), size)); err != nil {
+		writer.err = err
+		return 0
+	}
+	return 1
+}
+
+// TakeHeapSnapshot takes a snapshot of the heap and writes it to w in the
+// JSON .heapsnapshot format which Chrome DevTools loads.
+func (engine *Engine) TakeHeapSnapshot(w io.Writer) error {
+	writer := &heapSnapshotWriter{w: w}
+
+	if C.V8_Engine_TakeHeapSnapshot(engine.self, unsafe.Pointer(writer)) == 0 {
+		return errors.New("v8: couldn't take heap snapshot")
+	}
+
+	return writer.err
+}
+
+// HeapSnapshot is the heap graph of a .heapsnapshot file.
+type HeapSnapshot struct {
+	Nodes []*HeapNode // The first node is the root.
+}
+
+// HeapNode is an object in the heap graph.
+type HeapNode struct {
+	Type     string // "object", "closure", "string", "array", "hidden", ...
+	Name     string // Constructor name of objects, function name of closures.
+	ID       uint64
+	SelfSize int64
+	Edges    []*HeapEdge
+}
+
+// HeapEdge is a reference from one heap node to another.
+type HeapEdge struct {
+	Type string // "property", "element", "context", "internal", "hidden", "shortcut" or "weak".
+	Name string // Property name or element index.
+	To   *HeapNode
+}
+
+type heapSnapshotMeta struct {
+	NodeFields []string          `json:"node_fields"`
+	NodeTypes  []json.RawMessage `json:"node_types"`
+	EdgeFields []string          `json:"edge_fields"`
+	EdgeTypes  []json.RawMessage `json:"edge_types"`
+}
+
+type heapSnapshotFile struct {
+	Snapshot struct {
+		Meta heapSnapshotMeta `json:"meta"`
+	} `json:"snapshot"`
+	Nodes   []int64  `json:"nodes"`
+	Edges   []int64  `json:"edges"`
+	Strings []string `json:"strings"`
+}
+
+// Returns the index of the field and the enum values of the field type.
+func heapSnapshotField(fields []string, types []json.RawMessage, name string) (int, []string, error) {
+	for i, field := range fields {
+		if field != name {
+			continue
+		}
+		var enum []string
+		if i < len(types) {
+			// Enum types are arrays, other types are strings like "number".
+			json.Unmarshal(types[i], &enum)
+		}
+		return i, enum, nil
+	}
+	return 0, nil, fmt.Errorf("v8: heap snapshot without %q field", name)
+}
+
+// ReadHeapSnapshot reads a heap snapshot written by TakeHeapSnapshot.
+func ReadHeapSnapshot(r io.Reader) (*HeapSnapshot, error) {
+	var file heapSnapshotFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+
+	meta := file.Snapshot.Meta
+	nodeFieldCount := len(meta.NodeFields)
+	edgeFieldCount := len(meta.EdgeFields)
+	if nodeFieldCount == 0 || edgeFieldCount == 0 {
+		return nil, errors.New("v8: heap snapshot without meta data")
+	}
+
+	nodeType, nodeTypes, err := heapSnapshotField(meta.NodeFields, meta.NodeTypes, "type")
+	if err != nil {
+		return nil, err
+	}
+	nodeName, _, err := heapSnapshotField(meta.NodeFields, meta.NodeTypes, "name")
+	if err != nil {
+		return nil, err
+	}
+	nodeId, _, err := heapSnapshotField(meta.NodeFields, meta.NodeTypes, "id")
+	if err != nil {
+		return nil, err
+	}
+	nodeSelfSize, _, err := heapSnapshotField(meta.NodeFields, meta.NodeTypes, "self_size")
+	if err != nil {
+		return nil, err
+	}
+	nodeEdgeCount, _, err := heapSnapshotField(meta.NodeFields, meta.NodeTypes, "edge_count")
+	if err != nil {
+		return nil, err
+	}
+	edgeType, edgeTypes, err := heapSnapshotField(meta.EdgeFields, meta.EdgeTypes, "type")
+	if err != nil {
+		return nil, err
+	}
+	edgeName, _, err := heapSnapshotField(meta.EdgeFields, meta.EdgeTypes, "name_or_index")
+	if err != nil {
+		return nil, err
+	}
+	edgeTo, _, err := heapSnapshotField(meta.EdgeFields, meta.EdgeTypes, "to_node")
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := func(table []string, index int64) string {
+		if index < 0 || index >= int64(len(table)) {
+			return ""
+		}
+		return table[index]
+	}
+
+	snapshot := &HeapSnapshot{
+		Nodes: make([]*HeapNode, len(file.Nodes)/nodeFieldCount),
+	}
+
+	for i := range snapshot.Nodes {
+		fields := file.Nodes[i*nodeFieldCount:]
+		snapshot.Nodes[i] = &HeapNode{
+			Type:     lookup(nodeTypes, fields[nodeType]),
+			Name:     lookup(file.Strings, fields[nodeName]),
+			ID:       uint64(fields[nodeId]),
+			SelfSize: fields[nodeSelfSize],
+		}
+	}
+
+	edge := 0
+	for i, node := range snapshot.Nodes {
+		count := int(file.Nodes[i*nodeFieldCount+nodeEdgeCount])
+		node.Edges = make([]*HeapEdge, 0, count)
+
+		for j := 0; j < count; j, edge = j+1, edge+edgeFieldCount {
+			if edge+edgeFieldCount > len(file.Edges) {
+				return nil, errors.New("v8: heap snapshot edges out of range")
+			}
+			fields := file.Edges[edge:]
+
+			to := int(fields[edgeTo]) / nodeFieldCount
+			if to < 0 || to >= len(snapshot.Nodes) {
+				return nil, errors.New("v8: heap snapshot edge to unknown node")
+			}
+
+			e := &HeapEdge{
+				Type: lookup(edgeTypes, fields[edgeType]),
+				To:   snapshot.Nodes[to],
+			}
+
+			// Elements and hidden edges are named by index.
+			if e.Type == "element" || e.Type == "hidden" {
+				e.Name = strconv.FormatInt(fields[edgeName], 10)
+			} else {
+				e.Name = lookup(file.Strings, fields[edgeName])
+			}
+
+			node.Edges = append(node.Edges, e)
+		}
+	}
+
+	return snapshot, nil
+}
+
+// HeapSnapshot takes a heap snapshot and reads it back as a heap graph.
+func (engine *Engine) HeapSnapshot() (*HeapSnapshot, error) {
+	var buf bytes.Buffer
+	if err := engine.TakeHeapSnapshot(&buf); err != nil {
+		return nil, err
+	}
+	return ReadHeapSnapshot(&buf)
+}
+
+// Root returns the root node of the heap graph.
+func (s *HeapSnapshot) Root() *HeapNode {
+	if len(s.Nodes) == 0 {
+		return nil
+	}
+	return s.Nodes[0]
+}
+
+// Walk visits each node reachable from the root through strong references
+// once, in breadth-first order. The edges of a node are not followed when
+// fn returns false.
+func (s *HeapSnapshot) Walk(fn func(node *HeapNode) bool) {
+	root := s.Root()
+	if root == nil {
+		return
+	}
+
+	visited := map[*HeapNode]bool{root: true}
+	queue := []*HeapNode{root}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		if !fn(node) {
+			continue
+		}
+
+		for _, edge := range node.Edges {
+			if edge.Type == "weak" || visited[edge.To] {
+				continue
+			}
+			visited[edge.To] = true
+			queue = append(queue, edge.To)
+		}
+	}
+}
+
+// CountObjects returns the number of reachable objects created by the
+// given constructor.
+func (s *HeapSnapshot) CountObjects(constructor string) int {
+	count := 0
+	s.Walk(func(node *HeapNode) bool {
+		if node.Type == "object" && node.Name == constructor {
+			count++
+		}
+		return true
+	})
+	return count
+}
@@ -0,0 +1,63 @@
+package v8
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestTakeHeapSnapshot(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := engine.TakeHeapSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var file map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &file); err != nil {
+		t.Fatalf("snapshot is not valid JSON: %v", err)
+	}
+
+	for _, key := range []string{"snapshot", "nodes", "edges", "strings"} {
+		if _, exists := file[key]; !exists {
+			t.Fatalf("snapshot without %q", key)
+		}
+	}
+
+	snapshot, err := ReadHeapSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if snapshot.Root() == nil || len(snapshot.Root().Edges) == 0 {
+		t.Fatal("snapshot without root")
+	}
+}
+
+func TestHeapSnapshotLeak(t *testing.T) {
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		cs.Eval(`
+function HeapSnapshotLeak() {}
+var heapSnapshotLeaks = [];
+for (var i = 0; i < 10; i++) heapSnapshotLeaks.push(new HeapSnapshotLeak());
+`)
+
+		snapshot, err := engine.HeapSnapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := snapshot.CountObjects("HeapSnapshotLeak"); n != 10 {
+			t.Fatalf("expected 10 objects, got %d", n)
+		}
+
+		cs.Eval(`heapSnapshotLeaks = null;`)
+
+		snapshot, err = engine.HeapSnapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := snapshot.CountObjects("HeapSnapshotLeak"); n != 0 {
+			t.Fatalf("expected no objects, got %d", n)
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package v8
+
+import "testing"
+
+func TestHeapStatistics(t *testing.T) {
+	stats := engine.HeapStatistics()
+
+	if stats.TotalHeapSize == 0 || stats.UsedHeapSize == 0 {
+		t.Fatalf("unexpected heap statistics %+v", stats)
+	}
+
+	if stats.UsedHeapSize > stats.TotalHeapSize || stats.TotalHeapSize > stats.HeapSizeLimit {
+		t.Fatalf("unexpected heap statistics %+v", stats)
+	}
+
+	if len(stats.Spaces) == 0 {
+		t.Fatal("no heap spaces")
+	}
+
+	var used uint64
+	for _, space := range stats.Spaces {
+		if space.Name == "" {
+			t.Fatalf("heap space without name %+v", space)
+		}
+		used += space.UsedSize
+	}
+
+	if used == 0 || used > stats.TotalHeapSize {
+		t.Fatalf("unexpected sum of used space size %d", used)
+	}
+
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		cs.Eval(`var heapStatisticsTest = []; for (var i = 0; i < 100000; i++) heapStatisticsTest.push({i: i});`)
+	})
+
+	if after := engine.HeapStatistics(); after.UsedHeapSize <= stats.UsedHeapSize {
+		t.Fatalf("used heap size should grow, before %d after %d", stats.UsedHeapSize, after.UsedHeapSize)
+	}
+}
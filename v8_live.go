@@ -0,0 +1,319 @@
+package v8
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// LiveMap exposes a Go map to JavaScript without copying it. JavaScript
+// reads, writes, deletes and enumerates the entries of the map itself.
+// The keys of the map must be strings or integers. Maps and slices in the
+// map are exposed the same way.
+type LiveMap struct {
+	Map interface{}
+}
+
+// LiveSlice exposes a Go slice to JavaScript without copying it.
+// JavaScript reads and writes the elements of the slice itself, and
+// the Array.prototype methods, like push, work on it. Slice should be
+// a pointer to a slice, so the slice grown by JavaScript is visible in Go.
+// Maps and slices in the slice are exposed the same way.
+type LiveSlice struct {
+	Slice interface{}
+}
+
+// A Go map, or a pointer to a Go slice, viewed by JavaScript.
+type liveView struct {
+	value reflect.Value
+
+	// Stores a grown slice back to the map which holds it.
+	store func(slice reflect.Value)
+}
+
+// Returns the slice of a slice view.
+func (view *liveView) slice() reflect.Value {
+	return view.value.Elem()
+}
+
+// Sets the length of a slice view, zero values are added when it grows.
+func (view *liveView) setLength(length int) {
+	slice := view.slice()
+	if length <= slice.Len() {
+		slice.Set(slice.Slice(0, length))
+	}
+	for slice.Len() < length {
+		slice.Set(reflect.Append(slice, reflect.Zero(slice.Type().Elem())))
+	}
+	if view.store != nil {
+		view.store(slice)
+	}
+}
+
+// Converts a JS property name to a key of the map.
+func (view *liveView) mapKey(name string) (reflect.Value, bool) {
+	keyType := view.value.Type().Key()
+	key := reflect.New(keyType).Elem()
+
+	switch keyType.Kind() {
+	case reflect.String:
+		key.SetString(name)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(name, 10, 64)
+		if err != nil || key.OverflowInt(n) {
+			return key, false
+		}
+		key.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(name, 10, 64)
+		if err != nil || key.OverflowUint(n) {
+			return key, false
+		}
+		key.SetUint(n)
+	default:
+		return key, false
+	}
+
+	return key, true
+}
+
+// Returns the view of a JS value, or nil when it isn't a view.
+func liveViewOf(jsvalue *Value) *liveView {
+	if !jsvalue.IsObject() {
+		return nil
+	}
+	object := jsvalue.ToObject()
+	if object.InternalFieldCount() != 1 {
+		return nil
+	}
+	view, _ := object.GetInternalField(0).(*liveView)
+	return view
+}
+
+// Converts an element of a view, maps and slices are viewed too.
+func (engine *Engine) liveValueToJs(value reflect.Value) *Value {
+	switch value.Kind() {
+	case reflect.Map:
+		return engine.newLiveMap(value)
+	case reflect.Slice:
+		if value.CanAddr() {
+			value = value.Addr()
+		}
+		return engine.newLiveSlice(value, nil)
+	case reflect.Interface:
+		if !value.IsNil() {
+			return engine.liveValueToJs(value.Elem())
+		}
+	}
+	return engine.GoValueToJsValue(value)
+}
+
+func (engine *Engine) newLiveMap(value reflect.Value) *Value {
+	if value.Kind() != reflect.Map {
+		return engine.Undefined()
+	}
+	if value.IsNil() {
+		return engine.Null()
+	}
+
+	if engine.liveMapTemplate == nil {
+		engine.liveMapTemplate = engine.newLiveMapTemplate()
+	}
+
+	object := engine.NewInstanceOf(engine.liveMapTemplate)
+	object.ToObject().SetInternalField(0, &liveView{value: value})
+	return object
+}
+
+// Returns a slice view, value is a slice or a pointer to a slice.
+func (engine *Engine) newLiveSlice(value reflect.Value, store func(reflect.Value)) *Value {
+	if value.Kind() == reflect.Slice {
+		ptr := reflect.New(value.Type())
+		ptr.Elem().Set(value)
+		value = ptr
+	}
+
+	if value.Kind() != reflect.Ptr || value.Type().Elem().Kind() != reflect.Slice {
+		return engine.Undefined()
+	}
+	if value.IsNil() {
+		return engine.Null()
+	}
+
+	if engine.liveSliceTemplate == nil {
+		engine.liveSliceTemplate = engine.newLiveSliceTemplate()
+	}
+
+	object := engine.NewInstanceOf(engine.liveSliceTemplate).ToObject()
+	object.SetInternalField(0, &liveView{value, store})
+	object.SetPrototype(engine.NewArray(0).ToObject().GetPrototype())
+	return object.Value
+}
+
+func (engine *Engine) newLiveMapTemplate() *ObjectTemplate {
+	template := engine.NewObjectTemplate()
+	template.SetInternalFieldCount(1)
+
+	get := func(name string, info PropertyCallbackInfo) {
+		view := info.This().GetInternalField(0).(*liveView)
+		if key, ok := view.mapKey(name); ok {
+			value := view.value.MapIndex(key)
+			if !value.IsValid() {
+				return
+			}
+			if value.Kind() == reflect.Slice {
+				// Map values aren't addressable, so a grown slice is stored back.
+				info.ReturnValue().Set(engine.newLiveSlice(value, func(slice reflect.Value) {
+					view.value.SetMapIndex(key, slice)
+				}))
+				return
+			}
+			info.ReturnValue().Set(engine.liveValueToJs(value))
+		}
+	}
+
+	set := func(name string, jsvalue *Value, info PropertyCallbackInfo) {
+		view := info.This().GetInternalField(0).(*liveView)
+		if key, ok := view.mapKey(name); ok {
+			value := reflect.New(view.value.Type().Elem()).Elem()
+			engine.SetJsValueToGo(value, jsvalue)
+			view.value.SetMapIndex(key, value)
+			info.ReturnValue().Set(jsvalue)
+		}
+	}
+
+	query := func(name string, info PropertyCallbackInfo) {
+		view := info.This().GetInternalField(0).(*liveView)
+		if key, ok := view.mapKey(name); ok && view.value.MapIndex(key).IsValid() {
+			info.ReturnValue().SetInt32(int32(PA_None))
+		}
+	}
+
+	deleter := func(name string, info PropertyCallbackInfo) {
+		view := info.This().GetInternalField(0).(*liveView)
+		if key, ok := view.mapKey(name); ok {
+			view.value.SetMapIndex(key, reflect.Value{})
+			info.ReturnValue().SetBoolean(true)
+		}
+	}
+
+	enumerator := func(info PropertyCallbackInfo) {
+		view := info.This().GetInternalField(0).(*liveView)
+
+		keys := make([]string, 0, view.value.Len())
+		for _, key := range view.value.MapKeys() {
+			keys = append(keys, fmt.Sprint(key.Interface()))
+		}
+		sort.Strings(keys)
+
+		array := engine.NewArray(len(keys))
+		for i, key := range keys {
+			array.ToObject().SetElement(i, engine.NewString(key))
+		}
+		info.ReturnValue().Set(array)
+	}
+
+	template.SetNamedPropertyHandler(get, set, query, deleter, enumerator, nil)
+
+	// Integer keys are indexed properties in V8.
+	template.SetIndexedPropertyHandler(
+		func(index uint32, info PropertyCallbackInfo) {
+			get(strconv.FormatUint(uint64(index), 10), info)
+		},
+		func(index uint32, jsvalue *Value, info PropertyCallbackInfo) {
+			set(strconv.FormatUint(uint64(index), 10), jsvalue, info)
+		},
+		func(index uint32, info PropertyCallbackInfo) {
+			query(strconv.FormatUint(uint64(index), 10), info)
+		},
+		func(index uint32, info PropertyCallbackInfo) {
+			deleter(strconv.FormatUint(uint64(index), 10), info)
+		},
+		nil,
+		nil,
+	)
+
+	return template
+}
+
+func (engine *Engine) newLiveSliceTemplate() *ObjectTemplate {
+	template := engine.NewObjectTemplate()
+	template.SetInternalFieldCount(1)
+
+	template.SetIndexedPropertyHandler(
+		// get
+		func(index uint32, info PropertyCallbackInfo) {
+			view := info.This().GetInternalField(0).(*liveView)
+			if slice := view.slice(); int(index) < slice.Len() {
+				info.ReturnValue().Set(engine.liveValueToJs(slice.Index(int(index))))
+			}
+		},
+		// set
+		func(index uint32, jsvalue *Value, info PropertyCallbackInfo) {
+			view := info.This().GetInternalField(0).(*liveView)
+			if int(index) >= view.slice().Len() {
+				view.setLength(int(index) + 1)
+			}
+			engine.SetJsValueToGo(view.slice().Index(int(index)), jsvalue)
+			info.ReturnValue().Set(jsvalue)
+		},
+		// query
+		func(index uint32, info PropertyCallbackInfo) {
+			view := info.This().GetInternalField(0).(*liveView)
+			if int(index) < view.slice().Len() {
+				info.ReturnValue().SetInt32(int32(PA_DontDelete))
+			}
+		},
+		// delete
+		func(index uint32, info PropertyCallbackInfo) {
+			// Elements can't be deleted.
+			info.ReturnValue().SetBoolean(false)
+		},
+		// enum
+		func(info PropertyCallbackInfo) {
+			view := info.This().GetInternalField(0).(*liveView)
+			length := view.slice().Len()
+			array := engine.NewArray(length)
+			for i := 0; i < length; i++ {
+				array.ToObject().SetElement(i, engine.NewInteger(int64(i)))
+			}
+			info.ReturnValue().Set(array)
+		},
+		nil,
+	)
+
+	template.SetNamedPropertyHandler(
+		// get
+		func(name string, info PropertyCallbackInfo) {
+			view := info.This().GetInternalField(0).(*liveView)
+			switch name {
+			case "length":
+				info.ReturnValue().SetInt32(int32(view.slice().Len()))
+			case "toJSON":
+				info.ReturnValue().Set(engine.NewFunction(func(fc FunctionCallbackInfo) {
+					fc.ReturnValue().Set(engine.GoValueToJsValue(view.slice()))
+				}, nil).Value)
+			}
+		},
+		// set
+		func(name string, jsvalue *Value, info PropertyCallbackInfo) {
+			if name == "length" {
+				view := info.This().GetInternalField(0).(*liveView)
+				view.setLength(int(jsvalue.ToUint32()))
+				info.ReturnValue().Set(jsvalue)
+			}
+		},
+		// query
+		func(name string, info PropertyCallbackInfo) {
+			if name == "length" {
+				info.ReturnValue().SetInt32(int32(PA_DontEnum | PA_DontDelete))
+			}
+		},
+		nil,
+		nil,
+		nil,
+	)
+
+	return template
+}
@@ -0,0 +1,70 @@
+package v8
+
+import "reflect"
+import "testing"
+
+func TestLiveMap(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2}
+
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		cs.Global().SetProperty("m", engine.GoValueToJsValue(reflect.ValueOf(LiveMap{m})))
+
+		if keys := cs.Eval(`Object.keys(m).join()`).ToString(); keys != "a,b" {
+			t.Fatalf(`keys should be "a,b", not %q`, keys)
+		}
+
+		cs.Eval(`m.c = m.a + m.b; delete m.a`)
+	})
+
+	if !reflect.DeepEqual(m, map[string]int{"b": 2, "c": 3}) {
+		t.Fatalf("map should be updated, not %v", m)
+	}
+
+	m2 := map[int]string{1: "a"}
+
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		cs.Global().SetProperty("m", engine.GoValueToJsValue(reflect.ValueOf(LiveMap{m2})))
+
+		if value := cs.Eval(`m[2] = m[1] + "b"; m[2]`).ToString(); value != "ab" {
+			t.Fatalf(`m[2] should be "ab", not %q`, value)
+		}
+	})
+
+	if m2[2] != "ab" {
+		t.Fatalf(`m2[2] should be "ab", not %q`, m2[2])
+	}
+}
+
+func TestLiveSlice(t *testing.T) {
+	s := []int{1, 2, 3}
+	nested := map[string][]string{"a": {"x"}}
+
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		cs.Global().SetProperty("s", engine.GoValueToJsValue(reflect.ValueOf(LiveSlice{&s})))
+		cs.Global().SetProperty("n", engine.GoValueToJsValue(reflect.ValueOf(LiveMap{nested})))
+
+		if value := cs.Eval(`s.length`).ToInt32(); value != 3 {
+			t.Fatalf("length should be 3, not %d", value)
+		}
+
+		if value := cs.Eval(`s.map(function(x) { return x * 2 }).join()`).ToString(); value != "2,4,6" {
+			t.Fatalf(`value should be "2,4,6", not %q`, value)
+		}
+
+		cs.Eval(`s[0] = 10; s.push(4, 5)`)
+
+		if value := cs.Eval(`JSON.stringify(s)`).ToString(); value != "[10,2,3,4,5]" {
+			t.Fatalf(`json should be "[10,2,3,4,5]", not %q`, value)
+		}
+
+		cs.Eval(`s.length = 4; n.a.push("y")`)
+	})
+
+	if !reflect.DeepEqual(s, []int{10, 2, 3, 4}) {
+		t.Fatalf("slice should be updated, not %v", s)
+	}
+
+	if !reflect.DeepEqual(nested["a"], []string{"x", "y"}) {
+		t.Fatalf("nested slice should be updated, not %v", nested["a"])
+	}
+}
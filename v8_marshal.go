@@ -0,0 +1,577 @@
+package v8
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MarshalError is returned by Marshal and Unmarshal when a value can't be
+// converted.
+type MarshalError struct {
+	Path    string // Path of the value, like ".items[3].price", or "".
+	Message string
+}
+
+func (e *MarshalError) Error() string {
+	if e.Path == "" {
+		return "value: " + e.Message
+	}
+	return "field " + e.Path + ": " + e.Message
+}
+
+// A struct field with its 'js' struct tag options.
+type marshalField struct {
+	Name      string
+	Index     []int
+	OmitEmpty bool
+	String    bool // Numbers and booleans are strings in JS.
+}
+
+// Returns the fields of a struct type like encoding/json: the fields of the
+// embedded structs are promoted, and the tag `js:"name,omitempty,string"`
+// renames the fields, omits the empty values or quotes the numbers and
+// booleans. The fields with the tag `js:"-"` are skipped. The name of the
+// 'js-field' tag is used when there is no 'js' tag.
+func marshalFields(typeInfo reflect.Type) []marshalField {
+	fields := make([]marshalField, 0)
+
+	for i := 0; i < typeInfo.NumField(); i++ {
+		field := typeInfo.Field(i)
+
+		tag, hasTag := field.Tag.Lookup("js")
+		if tag == "-" {
+			continue
+		}
+		if !hasTag {
+			tag = strings.Split(field.Tag.Get("js-field"), ",")[0]
+		}
+
+		options := strings.Split(tag, ",")
+		name := options[0]
+
+		if embedded := embeddedStruct(field); embedded != nil && name == "" {
+			for _, inner := range marshalFields(embedded) {
+				inner.Index = append([]int{i}, inner.Index...)
+				fields = append(fields, inner)
+			}
+			continue
+		}
+
+		// Skip unexported fields.
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		mf := marshalField{Name: name, Index: []int{i}}
+		for _, option := range options[1:] {
+			switch option {
+			case "omitempty":
+				mf.OmitEmpty = true
+			case "string":
+				mf.String = true
+			}
+		}
+		fields = append(fields, mf)
+	}
+
+	// The shallowest field hides the promoted fields of the same name.
+	visible := make([]marshalField, 0, len(fields))
+	for _, field := range fields {
+		hidden := false
+		for _, other := range fields {
+			if other.Name == field.Name && len(other.Index) < len(field.Index) {
+				hidden = true
+				break
+			}
+		}
+		if !hidden && !containsMarshalField(visible, field.Name) {
+			visible = append(visible, field)
+		}
+	}
+
+	return visible
+}
+
+func containsMarshalField(fields []marshalField, name string) bool {
+	for _, field := range fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Reports whether a value is empty for the 'omitempty' tag option.
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Bool:
+		return !value.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return value.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return value.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return value.IsNil()
+	}
+	return false
+}
+
+// Marshal converts a Go value to a new JS value, the structs, maps and
+// slices are copied recursively. The struct fields follow the 'js' struct
+// tag, see marshalFields. The converters registered by RegisterConverter
+// and the ToJS methods are used first.
+func Marshal(engine *Engine, value interface{}) (*Value, error) {
+	return engine.marshal(reflect.ValueOf(value), "", false, make(map[marshalRef]bool))
+}
+
+// A pointer, map or slice which is marshalled, a slice is the same value
+// only with the same length.
+type marshalRef struct {
+	typ reflect.Type
+	ptr uintptr
+	len int
+}
+
+// The seen pointers, maps and slices are the ones which contain the value.
+func (engine *Engine) marshal(value reflect.Value, path string, quoted bool, seen map[marshalRef]bool) (*Value, error) {
+	if !value.IsValid() {
+		return engine.Null(), nil
+	}
+
+	if jsvalue, converted := engine.convertToJs(value); converted {
+		return jsvalue, nil
+	}
+
+	switch value.Type() {
+	case typeOfValue:
+		if value.IsNil() {
+			return engine.Null(), nil
+		}
+		return value.Interface().(*Value), nil
+	case typeOfTime:
+		return engine.NewDate(value.Interface().(time.Time)), nil
+	}
+
+	// A value which contains itself is an error, like in encoding/json.
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if !value.IsNil() && !(value.Kind() == reflect.Slice && value.Len() == 0) {
+			ref := marshalRef{value.Type(), value.Pointer(), 0}
+			if value.Kind() == reflect.Slice {
+				ref.len = value.Len()
+			}
+			if seen[ref] {
+				return nil, &MarshalError{path, "encountered a cycle via " + value.Type().String()}
+			}
+			seen[ref] = true
+			defer delete(seen, ref)
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		if quoted {
+			return engine.NewString(strconv.FormatBool(value.Bool())), nil
+		}
+		return engine.NewBoolean(value.Bool()), nil
+	case reflect.String:
+		return engine.NewString(value.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if quoted {
+			return engine.NewString(strconv.FormatInt(value.Int(), 10)), nil
+		}
+		return engine.NewNumber(float64(value.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if quoted {
+			return engine.NewString(strconv.FormatUint(value.Uint(), 10)), nil
+		}
+		return engine.NewNumber(float64(value.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		if quoted {
+			return engine.NewString(strconv.FormatFloat(value.Float(), 'g', -1, value.Type().Bits())), nil
+		}
+		return engine.NewNumber(value.Float()), nil
+	case reflect.Interface, reflect.Ptr:
+		if value.IsNil() {
+			return engine.Null(), nil
+		}
+		return engine.marshal(value.Elem(), path, quoted, seen)
+	case reflect.Slice:
+		if value.IsNil() {
+			return engine.Null(), nil
+		}
+		fallthrough
+	case reflect.Array:
+		array := engine.NewArray(value.Len())
+		for i := 0; i < value.Len(); i++ {
+			element, err := engine.marshal(value.Index(i), fmt.Sprintf("%s[%d]", path, i), false, seen)
+			if err != nil {
+				return nil, err
+			}
+			array.ToObject().SetElement(i, element)
+		}
+		return array, nil
+	case reflect.Map:
+		if value.IsNil() {
+			return engine.Null(), nil
+		}
+		keys := make([]string, 0, value.Len())
+		values := make(map[string]reflect.Value, value.Len())
+		for _, key := range value.MapKeys() {
+			switch key.Kind() {
+			case reflect.String,
+				reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			default:
+				return nil, &MarshalError{path, "unsupported map key type " + key.Type().String()}
+			}
+			name := fmt.Sprint(key.Interface())
+			keys = append(keys, name)
+			values[name] = value.MapIndex(key)
+		}
+		sort.Strings(keys)
+
+		object := engine.NewObject()
+		for _, key := range keys {
+			property, err := engine.marshal(values[key], path+"."+key, false, seen)
+			if err != nil {
+				return nil, err
+			}
+			object.ToObject().SetProperty(key, property)
+		}
+		return object, nil
+	case reflect.Struct:
+		object := engine.NewObject()
+		for _, field := range marshalFields(value.Type()) {
+			fieldValue := fieldByIndex(value, field.Index, false)
+			if !fieldValue.IsValid() || field.OmitEmpty && isEmptyValue(fieldValue) {
+				continue
+			}
+			property, err := engine.marshal(fieldValue, path+"."+field.Name, field.String, seen)
+			if err != nil {
+				return nil, err
+			}
+			object.ToObject().SetProperty(field.Name, property)
+		}
+		return object, nil
+	}
+
+	return nil, &MarshalError{path, "unsupported type " + value.Type().String()}
+}
+
+// Unmarshal sets the Go value pointed to by target to a copy of a JS value,
+// the objects and arrays are copied recursively. The struct fields follow
+// the 'js' struct tag, see marshalFields, and the undefined properties are
+// skipped. An interface{} is set to a bool, float64, string, time.Time,
+// []interface{} or map[string]interface{}. The converters registered by
+// RegisterConverter and the FromJS methods are used first.
+func Unmarshal(value *Value, target interface{}) error {
+	ptr := reflect.ValueOf(target)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return &MarshalError{"", fmt.Sprintf("Unmarshal needs a non-nil pointer, not %T", target)}
+	}
+	return value.engine.unmarshal(value, ptr.Elem(), "", false, nil)
+}
+
+// The ancestors are the objects which contain the value.
+func (engine *Engine) unmarshal(jsvalue *Value, field reflect.Value, path string, quoted bool, ancestors []*Value) error {
+	if converted, err := engine.convertFromJs(field, jsvalue); converted {
+		if err != nil {
+			return &MarshalError{path, err.Error()}
+		}
+		return nil
+	}
+
+	expected := func(typeName string) error {
+		return &MarshalError{path, "expected " + typeName + ", not " + jsTypeName(jsvalue)}
+	}
+
+	goType := field.Type()
+	switch goType {
+	case typeOfValue:
+		field.Set(reflect.ValueOf(jsvalue))
+		return nil
+	case typeOfTime:
+		if !jsvalue.IsDate() {
+			return expected("date")
+		}
+		field.Set(reflect.ValueOf(jsvalue.ToTime()))
+		return nil
+	}
+
+	// The quoted numbers and booleans are parsed from strings.
+	if quoted {
+		switch goType.Kind() {
+		case reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64:
+			if !jsvalue.IsString() {
+				return expected("string")
+			}
+			return unmarshalQuoted(jsvalue.ToString(), field, path)
+		}
+	}
+
+	switch goType.Kind() {
+	case reflect.Bool:
+		if !jsvalue.IsBoolean() {
+			return expected("boolean")
+		}
+		field.SetBool(jsvalue.ToBoolean())
+	case reflect.String:
+		if !jsvalue.IsString() {
+			return expected("string")
+		}
+		field.SetString(jsvalue.ToString())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !jsvalue.IsNumber() {
+			return expected("number")
+		}
+		n := jsvalue.ToNumber()
+		if n != math.Trunc(n) {
+			return expected("integer")
+		}
+		if n < math.MinInt64 || n >= math.MaxInt64 || field.OverflowInt(int64(n)) {
+			return &MarshalError{path, fmt.Sprintf("number %v overflows %s", n, goType)}
+		}
+		field.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !jsvalue.IsNumber() {
+			return expected("number")
+		}
+		n := jsvalue.ToNumber()
+		if n != math.Trunc(n) {
+			return expected("integer")
+		}
+		if n < 0 || n >= math.MaxUint64 || field.OverflowUint(uint64(n)) {
+			return &MarshalError{path, fmt.Sprintf("number %v overflows %s", n, goType)}
+		}
+		field.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		if !jsvalue.IsNumber() {
+			return expected("number")
+		}
+		field.SetFloat(jsvalue.ToNumber())
+	case reflect.Ptr:
+		if jsvalue.IsNull() || jsvalue.IsUndefined() {
+			field.Set(reflect.Zero(goType))
+			return nil
+		}
+		if field.IsNil() {
+			field.Set(reflect.New(goType.Elem()))
+		}
+		return engine.unmarshal(jsvalue, field.Elem(), path, quoted, ancestors)
+	case reflect.Interface:
+		if jsvalue.IsNull() || jsvalue.IsUndefined() {
+			field.Set(reflect.Zero(goType))
+			return nil
+		}
+		if goType.NumMethod() != 0 {
+			return &MarshalError{path, "unsupported type " + goType.String()}
+		}
+		var value interface{}
+		if err := engine.unmarshalInterface(jsvalue, &value, path, ancestors); err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(&value).Elem())
+	case reflect.Slice:
+		if jsvalue.IsNull() || jsvalue.IsUndefined() {
+			field.Set(reflect.Zero(goType))
+			return nil
+		}
+		if !jsvalue.IsArray() {
+			return expected("array")
+		}
+		length := jsvalue.ToArray().Length()
+		field.Set(reflect.MakeSlice(goType, length, length))
+		fallthrough
+	case reflect.Array:
+		if !jsvalue.IsArray() {
+			return expected("array")
+		}
+		var err error
+		if ancestors, err = withAncestor(ancestors, jsvalue, path); err != nil {
+			return err
+		}
+		array := jsvalue.ToArray()
+		for i := 0; i < array.Length() && i < field.Len(); i++ {
+			if err := engine.unmarshal(array.GetElement(i), field.Index(i), fmt.Sprintf("%s[%d]", path, i), false, ancestors); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if jsvalue.IsNull() || jsvalue.IsUndefined() {
+			field.Set(reflect.Zero(goType))
+			return nil
+		}
+		if !jsvalue.IsObject() || jsvalue.IsArray() {
+			return expected("object")
+		}
+		var err error
+		if ancestors, err = withAncestor(ancestors, jsvalue, path); err != nil {
+			return err
+		}
+		if field.IsNil() {
+			field.Set(reflect.MakeMap(goType))
+		}
+		object := jsvalue.ToObject()
+		names := object.GetOwnPropertyNames()
+		for i := 0; i < names.Length(); i++ {
+			name := names.GetElement(i).ToString()
+
+			key := reflect.New(goType.Key()).Elem()
+			if err := unmarshalQuoted(name, key, path+"."+name); err != nil {
+				return err
+			}
+
+			value := reflect.New(goType.Elem()).Elem()
+			if err := engine.unmarshal(object.GetProperty(name), value, path+"."+name, false, ancestors); err != nil {
+				return err
+			}
+			field.SetMapIndex(key, value)
+		}
+	case reflect.Struct:
+		if jsvalue.IsNull() || jsvalue.IsUndefined() {
+			return nil
+		}
+		if !jsvalue.IsObject() || jsvalue.IsArray() {
+			return expected("object")
+		}
+		var err error
+		if ancestors, err = withAncestor(ancestors, jsvalue, path); err != nil {
+			return err
+		}
+		object := jsvalue.ToObject()
+		for _, mf := range marshalFields(goType) {
+			property := object.GetProperty(mf.Name)
+			if property.IsUndefined() {
+				continue
+			}
+			target := fieldByIndex(field, mf.Index, true)
+			if !target.IsValid() {
+				return &MarshalError{path + "." + mf.Name, "cannot set embedded pointer to unexported struct"}
+			}
+			if err := engine.unmarshal(property, target, path+"."+mf.Name, mf.String, ancestors); err != nil {
+				return err
+			}
+		}
+	default:
+		return &MarshalError{path, "unsupported type " + goType.String()}
+	}
+
+	return nil
+}
+
+// Sets a string, a number or a boolean from its text, used for the quoted
+// fields and the map keys.
+func unmarshalQuoted(text string, field reflect.Value, path string) error {
+	var err error
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(text); err == nil {
+			field.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(text, 10, field.Type().Bits()); err == nil {
+			field.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		if n, err = strconv.ParseUint(text, 10, field.Type().Bits()); err == nil {
+			field.SetUint(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(text, field.Type().Bits()); err == nil {
+			field.SetFloat(f)
+		}
+	default:
+		return &MarshalError{path, "unsupported type " + field.Type().String()}
+	}
+
+	if err != nil {
+		return &MarshalError{path, fmt.Sprintf("invalid %s %q", field.Type(), text)}
+	}
+	return nil
+}
+
+// Sets an interface{} to the Go value of a JS value.
+func (engine *Engine) unmarshalInterface(jsvalue *Value, value *interface{}, path string, ancestors []*Value) error {
+	switch {
+	case jsvalue.IsNull() || jsvalue.IsUndefined():
+		*value = nil
+	case jsvalue.IsBoolean():
+		*value = jsvalue.ToBoolean()
+	case jsvalue.IsNumber():
+		*value = jsvalue.ToNumber()
+	case jsvalue.IsString():
+		*value = jsvalue.ToString()
+	case jsvalue.IsDate():
+		*value = jsvalue.ToTime()
+	case jsvalue.IsArray():
+		var err error
+		if ancestors, err = withAncestor(ancestors, jsvalue, path); err != nil {
+			return err
+		}
+		array := jsvalue.ToArray()
+		elements := make([]interface{}, array.Length())
+		for i := range elements {
+			if err := engine.unmarshalInterface(array.GetElement(i), &elements[i], fmt.Sprintf("%s[%d]", path, i), ancestors); err != nil {
+				return err
+			}
+		}
+		*value = elements
+	case jsvalue.IsFunction():
+		return &MarshalError{path, "unsupported JS type function"}
+	case jsvalue.IsObject():
+		var err error
+		if ancestors, err = withAncestor(ancestors, jsvalue, path); err != nil {
+			return err
+		}
+		object := jsvalue.ToObject()
+		names := object.GetOwnPropertyNames()
+		properties := make(map[string]interface{}, names.Length())
+		for i := 0; i < names.Length(); i++ {
+			name := names.GetElement(i).ToString()
+			var property interface{}
+			if err := engine.unmarshalInterface(object.GetProperty(name), &property, path+"."+name, ancestors); err != nil {
+				return err
+			}
+			properties[name] = property
+		}
+		*value = properties
+	default:
+		return &MarshalError{path, "unsupported JS type " + jsTypeName(jsvalue)}
+	}
+	return nil
+}
+
+// Returns the ancestors with an object appended, or an error when the
+// ancestors contain the object already, so when it contains itself.
+func withAncestor(ancestors []*Value, object *Value, path string) ([]*Value, error) {
+	for _, ancestor := range ancestors {
+		if ancestor.StrictEquals(object) {
+			return nil, &MarshalError{path, "cyclic object"}
+		}
+	}
+	return append(ancestors, object), nil
+}
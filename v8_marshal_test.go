@@ -0,0 +1,171 @@
+package v8
+
+import "reflect"
+import "testing"
+
+type MarshalItem struct {
+	Name  string  `js:"name"`
+	Price float64 `js:"price"`
+}
+
+type MarshalBase struct {
+	ID int64 `js:"id,string"`
+}
+
+type MarshalOrder struct {
+	MarshalBase
+	Items    []MarshalItem     `js:"items"`
+	Tags     map[string]int    `js:"tags,omitempty"`
+	Note     *string           `js:"note"`
+	Internal string            `js:"-"`
+	Extra    interface{}       `js:"extra,omitempty"`
+	Counts   map[int]bool      `js:"counts,omitempty"`
+	Parent   *MarshalOrder     `js:"parent,omitempty"`
+	Labels   map[string]string `js:"labels"`
+}
+
+func TestMarshal(t *testing.T) {
+	order := MarshalOrder{
+		MarshalBase: MarshalBase{ID: 42},
+		Items:       []MarshalItem{{"apple", 1.5}, {"pear", 2}},
+		Internal:    "secret",
+		Counts:      map[int]bool{1: true},
+		Parent:      &MarshalOrder{MarshalBase: MarshalBase{ID: 1}},
+	}
+
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		value, err := Marshal(engine, order)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		json := string(ToJSON(value))
+		expected := `{"id":"42","items":[{"name":"apple","price":1.5},{"name":"pear","price":2}],"note":null,"counts":{"1":true},"parent":{"id":"1","items":null,"note":null,"labels":null},"labels":null}`
+		if json != expected {
+			t.Fatalf("json should be %s not %s", expected, json)
+		}
+
+		var result MarshalOrder
+		if err := Unmarshal(value, &result); err != nil {
+			t.Fatal(err)
+		}
+		order.Internal = ""
+		if !reflect.DeepEqual(result, order) {
+			t.Fatalf("unmarshaled value should be %+v not %+v", order, result)
+		}
+
+		var generic interface{}
+		if err := Unmarshal(cs.Eval(`({a: [1, "b", true, null]})`), &generic); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(generic, map[string]interface{}{"a": []interface{}{1.0, "b", true, nil}}) {
+			t.Fatalf("unexpected value %#v", generic)
+		}
+
+		for code, message := range map[string]string{
+			`({items: [{}, {}, {}, {price: "1"}]})`: "field .items[3].price: expected number, not string",
+			`({id: 1})`:                             "field .id: expected string, not number",
+			`({id: "x"})`:                           `field .id: invalid int64 "x"`,
+			`({counts: {a: true}})`:                 `field .counts.a: invalid int "a"`,
+			`[]`:                                    "value: expected object, not array",
+		} {
+			err := Unmarshal(cs.Eval(code), &result)
+			if err == nil || err.Error() != message {
+				t.Fatalf("%s: error should be %q not %v", code, message, err)
+			}
+		}
+	})
+}
+
+type MarshalNode struct {
+	Name string       `js:"name"`
+	Next *MarshalNode `js:"next"`
+}
+
+func TestMarshalCycle(t *testing.T) {
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		node := &MarshalNode{Name: "a"}
+		node.Next = &MarshalNode{Name: "b", Next: node}
+
+		cyclicMap := map[string]interface{}{}
+		cyclicMap["self"] = cyclicMap
+
+		cyclicSlice := []interface{}{nil}
+		cyclicSlice[0] = cyclicSlice
+
+		for _, test := range []struct {
+			value   interface{}
+			message string
+		}{
+			{node, "field .next.next: encountered a cycle via *v8.MarshalNode"},
+			{cyclicMap, "field .self: encountered a cycle via map[string]interface {}"},
+			{cyclicSlice, "field [0]: encountered a cycle via []interface {}"},
+		} {
+			if _, err := Marshal(engine, test.value); err == nil || err.Error() != test.message {
+				t.Fatalf("error should be %q not %v", test.message, err)
+			}
+		}
+
+		// The values which are shared but not cyclic are copied.
+		shared := &MarshalItem{Name: "x"}
+		value, err := Marshal(engine, []*MarshalItem{shared, shared})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if json := string(ToJSON(value)); json != `[{"name":"x","price":0},{"name":"x","price":0}]` {
+			t.Fatalf("unexpected json %s", json)
+		}
+
+		cyclic := cs.Eval(`var o = {name: "a", next: {name: "b"}}; o.next.next = o; o`)
+
+		var generic interface{}
+		var properties map[string]interface{}
+		var result MarshalNode
+		for _, target := range []interface{}{&generic, &properties, &result} {
+			err := Unmarshal(cyclic, target)
+			if err == nil || err.Error() != "field .next.next: cyclic object" {
+				t.Fatalf("%T: error should be about the cycle not %v", target, err)
+			}
+		}
+
+		if err := Unmarshal(cs.Eval(`var x = {name: "x"}; ({a: x, b: [x, x]})`), &generic); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+type marshalHidden struct {
+	Secret int
+}
+
+type MarshalWithHidden struct {
+	*marshalHidden
+	Name string
+}
+
+func TestMarshalUnexportedEmbedded(t *testing.T) {
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		value, err := Marshal(engine, MarshalWithHidden{Name: "x"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if json := string(ToJSON(value)); json != `{"Name":"x"}` {
+			t.Fatalf("unexpected json %s", json)
+		}
+
+		var result MarshalWithHidden
+		err = Unmarshal(cs.Eval(`({Secret: 1, Name: "x"})`), &result)
+		if err == nil || err.Error() != "field .Secret: cannot set embedded pointer to unexported struct" {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		// The fields are set when the pointer isn't nil.
+		result = MarshalWithHidden{marshalHidden: &marshalHidden{}}
+		if err := Unmarshal(cs.Eval(`({Secret: 1, Name: "x"})`), &result); err != nil {
+			t.Fatal(err)
+		}
+		if result.Secret != 1 || result.Name != "x" {
+			t.Fatalf("unexpected value %+v", result)
+		}
+	})
+}
@@ -70,7 +70,7 @@ func (o *Object) SetProperty(key string, value *Value) bool {
 
 func (o *Object) GetProperty(key string) *Value {
 	keyPtr := unsafe.Pointer((*reflect.StringHeader)(unsafe.Pointer(&key)).Data)
-	return newValue(o.engine, C.V8_Object_GetProperty(
+	return o.engine.newScopedValue(C.V8_Object_GetProperty(
 		o.self, (*C.char)(keyPtr), C.int(len(key)),
 	))
 }
@@ -82,7 +82,7 @@ func (o *Object) SetElement(index int, value *Value) bool {
 }
 
 func (o *Object) GetElement(index int) *Value {
-	return newValue(o.engine, C.V8_Object_GetElement(o.self, C.uint32_t(index)))
+	return o.engine.newScopedValue(C.V8_Object_GetElement(o.self, C.uint32_t(index)))
 }
 
 func (o *Object) GetPropertyAttributes(key string) PropertyAttribute {
@@ -133,6 +133,8 @@ func (o *Object) SetAccessor(
 }
 
 func (o *Object) setAccessor(info *accessorInfo) {
+	o.engine.checkContextScope()
+
 	keyPtr := unsafe.Pointer((*reflect.StringHeader)(unsafe.Pointer(&info.key)).Data)
 	var getterPointer, setterPointer unsafe.Pointer
 	if info.getter != nil {
@@ -212,7 +214,7 @@ func (o *Object) DeleteElement(index int) bool {
 // be enumerated by a for-in statement over this object.
 //
 func (o *Object) GetPropertyNames() *Array {
-	return newValue(o.engine, C.V8_Object_GetPropertyNames(o.self)).ToArray()
+	return o.engine.newScopedValue(C.V8_Object_GetPropertyNames(o.self)).ToArray()
 }
 
 // This function has the same functionality as GetPropertyNames but
@@ -220,7 +222,7 @@ func (o *Object) GetPropertyNames() *Array {
 // prototype objects.
 //
 func (o *Object) GetOwnPropertyNames() *Array {
-	return newValue(o.engine, C.V8_Object_GetOwnPropertyNames(o.self)).ToArray()
+	return o.engine.newScopedValue(C.V8_Object_GetOwnPropertyNames(o.self)).ToArray()
 }
 
 // Get the prototype object.  This does not skip objects marked to
@@ -228,7 +230,7 @@ func (o *Object) GetOwnPropertyNames() *Array {
 // handler.
 //
 func (o *Object) GetPrototype() *Object {
-	return newValue(o.engine, C.V8_Object_GetPrototype(o.self)).ToObject()
+	return o.engine.newScopedValue(C.V8_Object_GetPrototype(o.self)).ToObject()
 }
 
 // Set the prototype object.  This does not skip objects marked to
@@ -255,7 +257,7 @@ func (o *Object) DeleteHiddenValue(key string) bool{
 }
 
 func (o *Object) GetConstructorName() string{
-	return newValue(o.engine, C.V8_Object_GetConstructorName(o.self)).ToString()
+	return o.engine.newScopedValue(C.V8_Object_GetConstructorName(o.self)).ToString()
 }
 
 func (o *Object) SetAlignedPointerInInternalField(index int, val_ptr unsafe.Pointer) {
@@ -268,7 +270,7 @@ func (o *Object) GetAlignedPointerFromInternalField(index int) unsafe.Pointer{
 
 func (o *Object) GetRealNamedProperty(key string) *Value {
 	keyPtr := unsafe.Pointer((*reflect.StringHeader)(unsafe.Pointer(&key)).Data)
-	return newValue(o.engine, C.V8_Object_GetRealNamedProperty(o.self, (*C.char)(keyPtr)))
+	return o.engine.newScopedValue(C.V8_Object_GetRealNamedProperty(o.self, (*C.char)(keyPtr)))
 }
 
 func (o *Object) HasRealNamedProperty(key string) bool{
@@ -0,0 +1,242 @@
+package v8
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// A minimal encoder for the pprof profile format, see
+// https://github.com/google/pprof/blob/master/proto/profile.proto
+// Only the messages used by the JS profilers are supported.
+
+// Field numbers of the profile.proto messages.
+const (
+	pprofProfileSampleType    = 1
+	pprofProfileSample        = 2
+	pprofProfileLocation      = 4
+	pprofProfileFunction      = 5
+	pprofProfileStringTable   = 6
+	pprofProfileTimeNanos     = 9
+	pprofProfileDurationNanos = 10
+	pprofProfilePeriodType    = 11
+	pprofProfilePeriod        = 12
+
+	pprofValueTypeType = 1
+	pprofValueTypeUnit = 2
+
+	pprofSampleLocationID = 1
+	pprofSampleValue      = 2
+
+	pprofLocationID   = 1
+	pprofLocationLine = 4
+
+	pprofLineFunctionID = 1
+	pprofLineLine       = 2
+
+	pprofFunctionID         = 1
+	pprofFunctionName       = 2
+	pprofFunctionSystemName = 3
+	pprofFunctionFilename   = 4
+	pprofFunctionStartLine  = 5
+)
+
+const (
+	pprofWireVarint = 0
+	pprofWireBytes  = 2
+)
+
+// Protocol buffer writer.
+type pprofBuffer struct {
+	data []byte
+}
+
+func (b *pprofBuffer) varint(x uint64) {
+	for x >= 0x80 {
+		b.data = append(b.data, byte(x)|0x80)
+		x >>= 7
+	}
+	b.data = append(b.data, byte(x))
+}
+
+func (b *pprofBuffer) tag(field, wire int) {
+	b.varint(uint64(field)<<3 | uint64(wire))
+}
+
+func (b *pprofBuffer) int64(field int, x int64) {
+	if x == 0 {
+		return
+	}
+	b.tag(field, pprofWireVarint)
+	b.varint(uint64(x))
+}
+
+func (b *pprofBuffer) uint64(field int, x uint64) {
+	if x == 0 {
+		return
+	}
+	b.tag(field, pprofWireVarint)
+	b.varint(x)
+}
+
+func (b *pprofBuffer) int64s(field int, xs []int64) {
+	packed := &pprofBuffer{}
+	for _, x := range xs {
+		packed.varint(uint64(x))
+	}
+	b.bytes(field, packed.data)
+}
+
+func (b *pprofBuffer) uint64s(field int, xs []uint64) {
+	packed := &pprofBuffer{}
+	for _, x := range xs {
+		packed.varint(x)
+	}
+	b.bytes(field, packed.data)
+}
+
+func (b *pprofBuffer) bytes(field int, data []byte) {
+	b.tag(field, pprofWireBytes)
+	b.varint(uint64(len(data)))
+	b.data = append(b.data, data...)
+}
+
+func (b *pprofBuffer) message(field int, msg *pprofBuffer) {
+	b.bytes(field, msg.data)
+}
+
+// A JS function in a pprof profile.
+type pprofFunction struct {
+	name     string
+	filename string
+	line     int
+}
+
+// A JS call site in a pprof profile.
+type pprofLocation struct {
+	function pprofFunction
+	line     int
+}
+
+// Builds a pprof profile from JS stacks.
+type pprofBuilder struct {
+	sampleTypes   [][2]string
+	periodType    [2]string
+	period        int64
+	timeNanos     int64
+	durationNanos int64
+
+	strings   []string
+	stringIDs map[string]int64
+	functions map[pprofFunction]uint64
+	locations map[pprofLocation]uint64
+
+	funcs   pprofBuffer
+	locs    pprofBuffer
+	samples pprofBuffer
+}
+
+func newPprofBuilder() *pprofBuilder {
+	return &pprofBuilder{
+		strings:   []string{""},
+		stringIDs: map[string]int64{"": 0},
+		functions: make(map[pprofFunction]uint64),
+		locations: make(map[pprofLocation]uint64),
+	}
+}
+
+func (p *pprofBuilder) stringID(s string) int64 {
+	if id, exists := p.stringIDs[s]; exists {
+		return id
+	}
+	id := int64(len(p.strings))
+	p.strings = append(p.strings, s)
+	p.stringIDs[s] = id
+	return id
+}
+
+func (p *pprofBuilder) functionID(f pprofFunction) uint64 {
+	if id, exists := p.functions[f]; exists {
+		return id
+	}
+	id := uint64(len(p.functions) + 1)
+	p.functions[f] = id
+
+	msg := &pprofBuffer{}
+	msg.uint64(pprofFunctionID, id)
+	msg.int64(pprofFunctionName, p.stringID(f.name))
+	msg.int64(pprofFunctionSystemName, p.stringID(f.name))
+	msg.int64(pprofFunctionFilename, p.stringID(f.filename))
+	msg.int64(pprofFunctionStartLine, int64(f.line))
+	p.funcs.message(pprofProfileFunction, msg)
+
+	return id
+}
+
+func (p *pprofBuilder) locationID(l pprofLocation) uint64 {
+	if id, exists := p.locations[l]; exists {
+		return id
+	}
+	id := uint64(len(p.locations) + 1)
+	p.locations[l] = id
+
+	line := &pprofBuffer{}
+	line.uint64(pprofLineFunctionID, p.functionID(l.function))
+	line.int64(pprofLineLine, int64(l.line))
+
+	msg := &pprofBuffer{}
+	msg.uint64(pprofLocationID, id)
+	msg.message(pprofLocationLine, line)
+	p.locs.message(pprofProfileLocation, msg)
+
+	return id
+}
+
+// Adds a sample, the stack is ordered from the leaf to the root.
+func (p *pprofBuilder) addSample(stack []pprofLocation, values ...int64) {
+	ids := make([]uint64, len(stack))
+	for i, l := range stack {
+		ids[i] = p.locationID(l)
+	}
+
+	msg := &pprofBuffer{}
+	msg.uint64s(pprofSampleLocationID, ids)
+	msg.int64s(pprofSampleValue, values)
+	p.samples.message(pprofProfileSample, msg)
+}
+
+func (p *pprofBuilder) valueType(typ, unit string) *pprofBuffer {
+	msg := &pprofBuffer{}
+	msg.int64(pprofValueTypeType, p.stringID(typ))
+	msg.int64(pprofValueTypeUnit, p.stringID(unit))
+	return msg
+}
+
+// Writes the gzip compressed profile.
+func (p *pprofBuilder) write(w io.Writer) error {
+	b := &pprofBuffer{}
+
+	for _, st := range p.sampleTypes {
+		b.message(pprofProfileSampleType, p.valueType(st[0], st[1]))
+	}
+	b.data = append(b.data, p.samples.data...)
+	b.data = append(b.data, p.locs.data...)
+	b.data = append(b.data, p.funcs.data...)
+
+	b.int64(pprofProfileTimeNanos, p.timeNanos)
+	b.int64(pprofProfileDurationNanos, p.durationNanos)
+	if p.periodType[0] != "" {
+		b.message(pprofProfilePeriodType, p.valueType(p.periodType[0], p.periodType[1]))
+	}
+	b.int64(pprofProfilePeriod, p.period)
+
+	// The string table goes last, all of the strings are interned by now.
+	for _, s := range p.strings {
+		b.bytes(pprofProfileStringTable, []byte(s))
+	}
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(b.data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
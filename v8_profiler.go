@@ -0,0 +1,156 @@
+package v8
+
+/*
+#include "v8_wrap.h"
+*/
+import "C"
+import (
+	"io"
+	"reflect"
+	"time"
+	"unsafe"
+)
+
+// CPUProfile is the call tree recorded by the CPU profiler.
+type CPUProfile struct {
+	Title     string
+	StartTime time.Duration // Relative to an arbitrary point, see EndTime.
+	EndTime   time.Duration
+	Root      *CPUProfileNode
+
+	interval time.Duration
+	nodes    map[uint]*CPUProfileNode
+}
+
+// CPUProfileNode is a JS function in the call tree of a CPUProfile.
+type CPUProfileNode struct {
+	ID           uint
+	FunctionName string
+	ScriptName   string
+	ScriptID     int
+	Line         int
+	Column       int
+	HitCount     uint // Number of samples taken in this function itself.
+	Parent       *CPUProfileNode
+	Children     []*CPUProfileNode
+}
+
+const defaultCPUProfileSamplingInterval = time.Millisecond
+
+// SetCPUProfileSamplingInterval changes the sampling interval of the CPU
+// profiler. It must be called when no profile is being recorded.
+func (engine *Engine) SetCPUProfileSamplingInterval(interval time.Duration) {
+	engine.cpuProfileInterval = interval
+	C.V8_Engine_SetCpuProfilerSamplingInterval(engine.self, C.int(interval/time.Microsecond))
+}
+
+// StartCPUProfile starts recording a CPU profile. Several profiles
+// can be recorded at once, starting a profile with the title of a
+// profile being recorded is ignored.
+func (engine *Engine) StartCPUProfile(title string) {
+	titlePtr := unsafe.Pointer((*reflect.StringHeader)(unsafe.Pointer(&title)).Data)
+	C.V8_Engine_StartCpuProfile(engine.self, (*C.char)(titlePtr), C.int(len(title)))
+}
+
+// StopCPUProfile stops recording the last started CPU profile and returns it.
+// It returns nil when no profile is being recorded.
+func (engine *Engine) StopCPUProfile() *CPUProfile {
+	profile := &CPUProfile{
+		interval: engine.cpuProfileInterval,
+		nodes:    make(map[uint]*CPUProfileNode),
+	}
+
+	if profile.interval == 0 {
+		profile.interval = defaultCPUProfileSamplingInterval
+	}
+
+	if C.V8_Engine_StopCpuProfile(engine.self, unsafe.Pointer(profile)) == 0 {
+		return nil
+	}
+
+	profile.nodes = nil
+	return profile
+}
+
+//export go_cpu_profile_header
+func go_cpu_profile_header(p unsafe.Pointer, title *C.char, startTime, endTime int64) {
+	profile := (*CPUProfile)(p)
+	profile.Title = C.GoString(title)
+	profile.StartTime = time.Duration(startTime) * time.Microsecond
+	profile.EndTime = time.Duration(endTime) * time.Microsecond
+}
+
+//export go_cpu_profile_node
+func go_cpu_profile_node(p unsafe.Pointer, parentId, id uint, functionName, scriptName *C.char, scriptId, line, column int, hitCount uint) {
+	profile := (*CPUProfile)(p)
+
+	node := &CPUProfileNode{
+		ID:           id,
+		FunctionName: C.GoString(functionName),
+		ScriptName:   C.GoString(scriptName),
+		ScriptID:     scriptId,
+		Line:         line,
+		Column:       column,
+		HitCount:     hitCount,
+	}
+
+	if parent, exists := profile.nodes[parentId]; exists && profile.Root != nil {
+		node.Parent = parent
+		parent.Children = append(parent.Children, node)
+	} else {
+		profile.Root = node
+	}
+
+	profile.nodes[id] = node
+}
+
+// Walk calls fn for each node of the call tree in depth-first order.
+func (p *CPUProfile) Walk(fn func(node *CPUProfileNode)) {
+	if p.Root != nil {
+		p.Root.walk(fn)
+	}
+}
+
+func (n *CPUProfileNode) walk(fn func(node *CPUProfileNode)) {
+	fn(n)
+	for _, child := range n.Children {
+		child.walk(fn)
+	}
+}
+
+// Returns a display name of the function like Chrome DevTools does.
+func (n *CPUProfileNode) displayName() string {
+	if n.FunctionName == "" {
+		return "(anonymous)"
+	}
+	return n.FunctionName
+}
+
+// WritePprof writes the profile in the gzip compressed protocol buffer
+// format read by 'go tool pprof'.
+func (p *CPUProfile) WritePprof(w io.Writer) error {
+	b := newPprofBuilder()
+	b.sampleTypes = [][2]string{{"samples", "count"}, {"cpu", "nanoseconds"}}
+	b.periodType = [2]string{"cpu", "nanoseconds"}
+	b.period = int64(p.interval)
+	b.durationNanos = int64(p.EndTime - p.StartTime)
+	b.timeNanos = time.Now().Add(-(p.EndTime - p.StartTime)).UnixNano()
+
+	p.Walk(func(node *CPUProfileNode) {
+		if node.HitCount == 0 || node == p.Root {
+			return
+		}
+
+		var stack []pprofLocation
+		for n := node; n != nil && n != p.Root; n = n.Parent {
+			stack = append(stack, pprofLocation{
+				function: pprofFunction{n.displayName(), n.ScriptName, n.Line},
+				line:     n.Line,
+			})
+		}
+
+		b.addSample(stack, int64(node.HitCount), int64(node.HitCount)*int64(p.interval))
+	})
+
+	return b.write(w)
+}
@@ -0,0 +1,70 @@
+package v8
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"testing"
+)
+
+func TestCPUProfile(t *testing.T) {
+	engine.StartCPUProfile("test")
+
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		script := engine.Compile([]byte(`
+		function fib(n) {
+			return n < 2 ? n : fib(n - 1) + fib(n - 2);
+		}
+		fib(27);
+		`), engine.NewScriptOrigin("fib.js", 0, 0))
+		cs.Run(script)
+	})
+
+	profile := engine.StopCPUProfile()
+	if profile == nil {
+		t.Fatal("profile == nil")
+	}
+
+	if profile.Title != "test" {
+		t.Fatalf("title should be %q not %q", "test", profile.Title)
+	}
+
+	if profile.Root == nil || profile.EndTime <= profile.StartTime {
+		t.Fatalf("unexpected profile %+v", profile)
+	}
+
+	var hits uint
+	profile.Walk(func(node *CPUProfileNode) {
+		if node.FunctionName == "fib" {
+			if node.ScriptName != "fib.js" || node.Line != 2 {
+				t.Fatalf("unexpected node %+v", node)
+			}
+			hits += node.HitCount
+		}
+	})
+
+	if hits == 0 {
+		t.Fatal("no samples in fib")
+	}
+
+	var buf bytes.Buffer
+	if err := profile.WritePprof(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte("fib.js")) {
+		t.Fatal("pprof profile doesn't contain the script name")
+	}
+
+	if engine.StopCPUProfile() != nil {
+		t.Fatal("no profile should be recording")
+	}
+}
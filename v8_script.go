@@ -5,6 +5,8 @@ package v8
 #include <stdlib.h>
 */
 import "C"
+import "context"
+import "errors"
 import "unsafe"
 import "reflect"
 import "runtime"
@@ -41,14 +43,54 @@ func (e *Engine) Compile(code []byte, origin *ScriptOrigin) *Script {
 	return result
 }
 
+// CompileE compiles the script like Compile, but returns the syntax error
+// as *Message, with the source line and position of the error.
+//
+func (e *Engine) CompileE(code []byte, origin *ScriptOrigin) (*Script, error) {
+	var script *Script
+
+	_, err := e.tryRun(func() *Value {
+		script = e.Compile(code, origin)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if script == nil {
+		return nil, errors.New("v8: couldn't compile script")
+	}
+
+	return script, nil
+}
+
 // Runs the script returning the resulting value.
 //
 func (cs ContextScope) Run(s *Script) *Value {
-	return newValue(cs.GetEngine(), C.V8_Script_Run(s.self))
+	return cs.GetEngine().newScopedValue(C.V8_Script_Run(s.self))
+}
+
+// RunE runs the script like Run, but returns uncaught exceptions as
+// *Message and heap exhaustion as ErrHeapLimit.
+//
+func (cs ContextScope) RunE(s *Script) (*Value, error) {
+	return cs.GetEngine().tryRun(func() *Value {
+		return cs.Run(s)
+	})
+}
+
+// RunContext runs the script like RunE, but terminates it when ctx is
+// cancelled or its deadline passes and then returns ErrTerminated.
+//
+func (cs ContextScope) RunContext(ctx context.Context, s *Script) (*Value, error) {
+	return cs.GetEngine().tryRunContext(ctx, func() *Value {
+		return cs.Run(s)
+	})
 }
 
 func (e *Engine) Run(s *Script) *Value{
-	return newValue(e, C.V8_Script_Run(s.self))
+	return e.newScopedValue(C.V8_Script_Run(s.self))
 }
 // The origin, within a file, of a script.
 //
@@ -1,10 +1,13 @@
 package v8
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestReturnValue(t *testing.T) {
@@ -104,3 +107,61 @@ let greeter = new Greeter("world");`))
 
 	runtime.GC()
 }
+
+func TestRunContext(t *testing.T) {
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		script := engine.Compile([]byte(`while(true) {}`), nil)
+
+		if _, err := cs.RunContext(ctx, script); err != ErrTerminated {
+			t.Fatalf("expected ErrTerminated, got %v", err)
+		}
+
+		if engine.IsExecutionTerminating() {
+			t.Fatal("engine is still terminating")
+		}
+
+		value, err := cs.EvalContext(context.Background(), `1 + 2`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value.ToInt32() != 3 {
+			t.Fatalf("value should be 3 not %d", value.ToInt32())
+		}
+
+		if _, err := cs.EvalContext(context.Background(), `throw new Error("oops")`); err == nil {
+			t.Fatal("expected exception")
+		}
+	})
+}
+
+func TestCompileE(t *testing.T) {
+	script, err := engine.CompileE([]byte("1 + 1"), nil)
+	if err != nil || script == nil {
+		t.Fatalf("unexpected result %v %v", script, err)
+	}
+
+	_, err = engine.CompileE([]byte("var a = 1;\nvar b = (a +;\n"), engine.NewScriptOrigin("syntax.js", 0, 0))
+	if err == nil {
+		t.Fatal("expected syntax error")
+	}
+
+	msg, ok := err.(*Message)
+	if !ok {
+		t.Fatalf("expected *Message, got %#v", err)
+	}
+
+	if !strings.Contains(msg.Message, "SyntaxError") {
+		t.Fatalf("unexpected message %q", msg.Message)
+	}
+
+	if msg.SourceLine != "var b = (a +;" || msg.Line != 2 || msg.StartColumn != 12 {
+		t.Fatalf("unexpected position %+v", msg)
+	}
+
+	if msg.ScriptResourceName != "syntax.js" {
+		t.Fatalf("unexpected script name %q", msg.ScriptResourceName)
+	}
+}
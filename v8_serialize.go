@@ -0,0 +1,184 @@
+package v8
+
+/*
+#include "v8_wrap.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// Serialize serializes the value by the structured clone algorithm, like
+// postMessage, so Dates, RegExps, Maps, Sets, typed arrays and cyclic
+// references are kept. A bound Go object is serialized by its MarshalBinary
+// method, see encoding.BinaryMarshaler, and its Go type is kept by the
+// package path and the type name, so the anonymous structs or the types
+// with the same name in a package can't be cloned. The values which can't
+// be cloned, like functions, are returned as *Exception. Must be called in
+// a context scope.
+func (engine *Engine) Serialize(value *Value) ([]byte, error) {
+	engine.checkContextScope()
+
+	var data []byte
+
+	_, err := engine.tryRunException(func() *Value {
+		var size C.int
+		buffer := C.V8_Serialize(engine.self, value.self, unsafe.Pointer(engine), &size)
+		if buffer != nil {
+			data = C.GoBytes(buffer, size)
+			C.free(buffer)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Deserialize deserializes a value serialized by Serialize, in this or
+// another engine, in the current context scope. A bound Go object is
+// created by the same Go type bound in this engine, by any JS name, and set
+// by the UnmarshalBinary method of its pointer, see
+// encoding.BinaryUnmarshaler. Malformed data is returned as *Exception.
+func (engine *Engine) Deserialize(data []byte) (*Value, error) {
+	engine.checkContextScope()
+
+	deserializer := &valueDeserializer{engine: engine}
+
+	var dataPtr unsafe.Pointer
+	if len(data) > 0 {
+		dataPtr = unsafe.Pointer(&data[0])
+	}
+
+	value, err := engine.tryRunException(func() *Value {
+		return newValue(engine, C.V8_Deserialize(engine.self, (*C.char)(dataPtr), C.int(len(data)), unsafe.Pointer(deserializer)))
+	})
+	if err == nil && value == nil {
+		return nil, errors.New("v8: couldn't deserialize value")
+	}
+	return value, err
+}
+
+// The state of Deserialize, used by the deserializer delegate.
+type valueDeserializer struct {
+	engine  *Engine
+	objects []*Value // Keeps the host objects until V8 has read them.
+}
+
+//export go_serializer_write_host_object
+func go_serializer_write_host_object(p, object unsafe.Pointer, data **C.char, size *C.int) {
+	engine := (*Engine)(p)
+	defer engine.recoverCallbackPanic(true)
+
+	payload, err := engine.marshalHostObject(newValue(engine, object))
+	if err != nil {
+		C.V8_Context_ThrowException2(engine.NewError(err.Error()).self)
+		return
+	}
+	*data = (*C.char)(C.CBytes(payload))
+	*size = C.int(len(payload))
+}
+
+//export go_deserializer_read_host_object
+func go_deserializer_read_host_object(p unsafe.Pointer, data *C.char, size C.int) unsafe.Pointer {
+	deserializer := (*valueDeserializer)(p)
+	engine := deserializer.engine
+	defer engine.recoverCallbackPanic(true)
+
+	object, err := engine.unmarshalHostObject(C.GoBytes(unsafe.Pointer(data), size))
+	if err != nil {
+		C.V8_Context_ThrowException2(engine.NewError(err.Error()).self)
+		return nil
+	}
+	deserializer.objects = append(deserializer.objects, object)
+	return object.self
+}
+
+// Returns the payload of a bound Go object, the length of the Go type name
+// as a uvarint, the name and the data of MarshalBinary.
+func (engine *Engine) marshalHostObject(value *Value) ([]byte, error) {
+	dyObj := GetDynamicObject(value)
+	if dyObj == nil {
+		return nil, errors.New("host object could not be cloned")
+	}
+
+	typeInfo := reflect.Indirect(dyObj.Target).Type()
+	bindInfo, exists := engine.bindTypes[typeInfo]
+	if !exists {
+		return nil, errors.New("host object could not be cloned")
+	}
+
+	name := hostTypeName(typeInfo)
+	if typeInfo.Name() == "" || len(engine.hostTypes(name)) > 1 {
+		return nil, fmt.Errorf("%s could not be cloned: the Go type %v has no unique name", bindInfo.Name, typeInfo)
+	}
+
+	marshaler, ok := dyObj.Target.Interface().(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, fmt.Errorf("%s could not be cloned: no MarshalBinary method", bindInfo.Name)
+	}
+	data, err := marshaler.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("%s could not be cloned: %v", bindInfo.Name, err)
+	}
+
+	payload := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(name)+len(data))
+	payload = payload[:binary.PutUvarint(payload, uint64(len(name)))]
+	payload = append(payload, name...)
+	return append(payload, data...), nil
+}
+
+// Creates a bound Go object from a payload of marshalHostObject.
+func (engine *Engine) unmarshalHostObject(payload []byte) (*Value, error) {
+	length, n := binary.Uvarint(payload)
+	if n <= 0 || length > uint64(len(payload)-n) {
+		return nil, errors.New("Unable to deserialize host object")
+	}
+	name := string(payload[n : n+int(length)])
+	data := payload[n+int(length):]
+
+	types := engine.hostTypes(name)
+	switch {
+	case len(types) == 0:
+		return nil, fmt.Errorf("%s could not be deserialized: the type isn't bound", name)
+	case len(types) > 1:
+		return nil, fmt.Errorf("%s could not be deserialized: more than one type has the name", name)
+	}
+	typeInfo := types[0]
+
+	target := reflect.New(typeInfo)
+	unmarshaler, ok := target.Interface().(encoding.BinaryUnmarshaler)
+	if !ok {
+		return nil, fmt.Errorf("%s could not be deserialized: no UnmarshalBinary method", name)
+	}
+	if err := unmarshaler.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("%s could not be deserialized: %v", name, err)
+	}
+
+	return engine.newBindObject(engine.bindTypes[typeInfo], target), nil
+}
+
+// Returns the name of a Go type in the payload, the package path and the
+// type name.
+func hostTypeName(typeInfo reflect.Type) string {
+	return typeInfo.PkgPath() + "." + typeInfo.Name()
+}
+
+// Returns the bound named types with the name, there are more than one
+// for the types declared by the same name in different functions.
+func (engine *Engine) hostTypes(name string) []reflect.Type {
+	var types []reflect.Type
+	for typeInfo := range engine.bindTypes {
+		if typeInfo.Name() != "" && hostTypeName(typeInfo) == name {
+			types = append(types, typeInfo)
+		}
+	}
+	return types
+}
@@ -0,0 +1,180 @@
+package v8
+
+import "fmt"
+import "strings"
+import "testing"
+
+type SerializePoint struct {
+	X, Y int
+}
+
+func (p *SerializePoint) MarshalBinary() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d,%d", p.X, p.Y)), nil
+}
+
+func (p *SerializePoint) UnmarshalBinary(data []byte) error {
+	_, err := fmt.Sscanf(string(data), "%d,%d", &p.X, &p.Y)
+	return err
+}
+
+type SerializeNoMarshal struct {
+	X int
+}
+
+func TestSerialize(t *testing.T) {
+	var data []byte
+
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		value := cs.Eval(`
+		var v = {
+			date: new Date(0),
+			re: /a+/g,
+			map: new Map([["k", 1]]),
+			set: new Set([2]),
+			bytes: new Uint8Array([1, 2, 3])
+		};
+		v.self = v;
+		v;
+		`)
+
+		var err error
+		data, err = engine.Serialize(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := engine.Serialize(cs.Eval(`({f: function() {}})`)); err == nil {
+			t.Fatal("functions should not be cloned")
+		} else if _, ok := err.(*Exception); !ok {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	})
+
+	// The values can be moved to another engine.
+	other := NewEngine()
+	other.NewContext(nil).Scope(func(cs ContextScope) {
+		value, err := other.Deserialize(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs.Global().SetProperty("v", value)
+
+		result := cs.Eval(`[
+			v.date instanceof Date && v.date.getTime(),
+			v.re.source + v.re.flags,
+			v.map.get("k"),
+			v.set.has(2),
+			v.bytes instanceof Uint8Array && v.bytes.join(""),
+			v.self === v
+		].join()`)
+		if result.ToString() != "0,a+g,1,true,123,true" {
+			t.Fatalf("unexpected value %q", result.ToString())
+		}
+
+		if _, err := other.Deserialize([]byte("not serialized")); err == nil {
+			t.Fatal("malformed data should not be deserialized")
+		}
+		if _, err := other.Deserialize(nil); err == nil {
+			t.Fatal("empty data should not be deserialized")
+		}
+	})
+}
+
+func TestSerializeBinding(t *testing.T) {
+	newTemplate := func(engine *Engine) *ObjectTemplate {
+		template := engine.NewObjectTemplate()
+		template.Bind("SerializePoint", SerializePoint{})
+		template.Bind("SerializeNoMarshal", SerializeNoMarshal{})
+		return template
+	}
+
+	var data []byte
+
+	engine := NewEngine()
+	engine.NewContext(newTemplate(engine)).Scope(func(cs ContextScope) {
+		var err error
+		data, err = engine.Serialize(cs.Eval(`
+		var p = new SerializePoint();
+		p.X = 1;
+		p.Y = 2;
+		[p, p];
+		`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = engine.Serialize(cs.Eval(`new SerializeNoMarshal()`))
+		if err == nil || !strings.Contains(err.Error(), "SerializeNoMarshal could not be cloned") {
+			t.Fatalf("unexpected error %v", err)
+		}
+	})
+
+	other := NewEngine()
+	other.NewContext(nil).Scope(func(cs ContextScope) {
+		if _, err := other.Deserialize(data); err == nil || !strings.Contains(err.Error(), "isn't bound") {
+			t.Fatalf("unexpected error %v", err)
+		}
+	})
+
+	other.NewContext(newTemplate(other)).Scope(func(cs ContextScope) {
+		value, err := other.Deserialize(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs.Global().SetProperty("v", value)
+
+		if result := cs.Eval(`v[0] === v[1] && v[0] instanceof SerializePoint`); !result.ToBoolean() {
+			t.Fatal("the bound object should keep its identity and type")
+		}
+
+		point, ok := GetDynamicObject(value.ToArray().GetElement(0)).Target.Interface().(*SerializePoint)
+		if !ok || *point != (SerializePoint{1, 2}) {
+			t.Fatalf("unexpected point %#v", point)
+		}
+	})
+
+	// The Go type is found by any JS name.
+	renamed := NewEngine()
+	template := renamed.NewObjectTemplate()
+	template.Bind("Point", SerializePoint{})
+	renamed.NewContext(template).Scope(func(cs ContextScope) {
+		value, err := renamed.Deserialize(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs.Global().SetProperty("v", value)
+
+		if result := cs.Eval(`v[0] instanceof Point && v[0].X`); result.ToInt32() != 1 {
+			t.Fatal("the bound object should have the type bound by another name")
+		}
+	})
+}
+
+func TestSerializeBindingCollision(t *testing.T) {
+	var first, second interface{}
+	{
+		type SerializeSame struct{ SerializePoint }
+		first = SerializeSame{}
+	}
+	{
+		type SerializeSame struct{ SerializePoint }
+		second = SerializeSame{}
+	}
+
+	engine := NewEngine()
+	template := engine.NewObjectTemplate()
+	template.Bind("First", first)
+	template.Bind("Second", second)
+	template.Bind("NewAnonymous", func() *struct{ SerializePoint } {
+		return &struct{ SerializePoint }{SerializePoint{1, 2}}
+	})
+
+	engine.NewContext(template).Scope(func(cs ContextScope) {
+		for _, code := range []string{`new First()`, `new Second()`, `NewAnonymous()`} {
+			_, err := engine.Serialize(cs.Eval(code))
+			if err == nil || !strings.Contains(err.Error(), "has no unique name") {
+				t.Fatalf("%s: unexpected error %v", code, err)
+			}
+		}
+	})
+}
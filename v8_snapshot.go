@@ -0,0 +1,41 @@
+package v8
+
+/*
+#include "v8_wrap.h"
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+)
+
+// CreateSnapshot runs the scripts in a new context and returns a startup
+// snapshot of the heap. Engines created with the snapshot in EngineOptions
+// start every new context with the globals defined by the scripts. Bound
+// Go functions and objects can't be used by the scripts.
+func CreateSnapshot(scripts ...[]byte) ([]byte, error) {
+	var (
+		scriptPtrs    = make([]*C.char, len(scripts)+1)
+		scriptLengths = make([]C.int, len(scripts)+1)
+		blob          C.V8_StartupData
+	)
+
+	for i, script := range scripts {
+		if len(script) > 0 {
+			scriptPtrs[i] = (*C.char)(unsafe.Pointer(&script[0]))
+			scriptLengths[i] = C.int(len(script))
+		}
+	}
+
+	msg := C.V8_CreateSnapshot(&scriptPtrs[0], &scriptLengths[0], C.int(len(scripts)), &blob)
+	if msg != nil {
+		return nil, (*Message)(msg)
+	}
+
+	if blob.data == nil {
+		return nil, errors.New("v8: couldn't create snapshot")
+	}
+	defer C.V8_DeleteSnapshot(&blob)
+
+	return C.GoBytes(unsafe.Pointer(blob.data), blob.size), nil
+}
@@ -0,0 +1,65 @@
+package v8
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestCreateSnapshot(t *testing.T) {
+	snapshot, err := CreateSnapshot(
+		[]byte(`var snapshotTest = {greet: function(name) { return "hello " + name; }};`),
+		[]byte(`snapshotTest.count = 42;`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot) == 0 {
+		t.Fatal("empty snapshot")
+	}
+
+	engine := NewEngineWithOptions(EngineOptions{Snapshot: snapshot})
+
+	for i := 0; i < 2; i++ {
+		engine.NewContext(nil).Scope(func(cs ContextScope) {
+			if s := cs.Eval(`snapshotTest.greet("v8")`).ToString(); s != "hello v8" {
+				t.Fatalf("unexpected result %q", s)
+			}
+			if n := cs.Eval(`snapshotTest.count++`).ToInteger(); n != 42 {
+				t.Fatalf("every context should start with the snapshot, got %d", n)
+			}
+		})
+	}
+}
+
+func TestCreateSnapshotError(t *testing.T) {
+	_, err := CreateSnapshot([]byte(`var a = 1;`), []byte(`throw new Error("snapshot error");`))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	msg, ok := err.(*Message)
+	if !ok || msg.Message != "Uncaught Error: snapshot error" {
+		t.Fatalf("unexpected error %#v", err)
+	}
+}
+
+func BenchmarkSnapshotNewContext(b *testing.B) {
+	code, err := ioutil.ReadFile("samples/underscore.js")
+	if err != nil {
+		b.Skip(err)
+	}
+
+	snapshot, err := CreateSnapshot(code)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	engine := NewEngineWithOptions(EngineOptions{Snapshot: snapshot})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		engine.NewContext(nil).Scope(func(cs ContextScope) {
+			cs.Eval(`_.map([1, 2, 3], function(n) { return n * 2; })`)
+		})
+	}
+}
@@ -168,6 +168,16 @@ func (ot *ObjectTemplate) SetProperty(key string, value *Value, attribs Property
 	)
 }
 
+// SetFunctionTemplate sets a property to the function of a function
+// template. Every instance in a context shares the same function.
+func (ot *ObjectTemplate) SetFunctionTemplate(key string, ft *FunctionTemplate, attribs PropertyAttribute) {
+	keyPtr := unsafe.Pointer((*reflect.StringHeader)(unsafe.Pointer(&key)).Data)
+
+	C.V8_ObjectTemplate_SetFunctionTemplate(
+		ot.self, (*C.char)(keyPtr), C.int(len(key)), ft.self, C.int(attribs),
+	)
+}
+
 func (ot *ObjectTemplate) SetInternalFieldCount(count int) {
 	C.V8_ObjectTemplate_SetInternalFieldCount(ot.self, C.int(count))
 	ot.internalFieldCount = count
@@ -261,7 +271,7 @@ func (ot *ObjectTemplate) SetNamedPropertyHandler(
 		queryPointer,
 		deleterPointer,
 		enumeratorPointer,
-		unsafe.Pointer(&data))
+		unsafe.Pointer(&info.data))
 }
 
 func (ot *ObjectTemplate) SetAccessCheckCallbacks(
@@ -323,7 +333,7 @@ func (ot *ObjectTemplate) SetIndexedPropertyHandler(
 		queryPointer,
 		deleterPointer,
 		enumeratorPointer,
-		unsafe.Pointer(&data))
+		unsafe.Pointer(&info.data))
 }
 
 type PropertyCallbackInfo struct {
@@ -415,6 +425,8 @@ func go_accessor_callback(typ C.AccessorDataEnum, info *C.V8_AccessorCallbackInf
 	}
 	gname := *(*string)(unsafe.Pointer(&name))
 	gcontext := (*Context)(context)
+	defer gcontext.engine.recoverCallbackPanic(true)
+
 	switch typ {
 	case C.OTA_Getter:
 		(*(*AccessorGetterCallback)(info.callback))(
@@ -437,6 +449,8 @@ func go_named_property_callback(typ C.PropertyDataEnum, info *C.V8_PropertyCallb
 		gname = C.GoString(info.key)
 	}
 	gcontext := (*Context)(context)
+	defer gcontext.engine.recoverCallbackPanic(true)
+
 	switch typ {
 	case C.OTP_Getter:
 		(*(*NamedPropertyGetterCallback)(info.callback))(
@@ -461,6 +475,8 @@ func go_named_property_callback(typ C.PropertyDataEnum, info *C.V8_PropertyCallb
 //export go_indexed_property_callback
 func go_indexed_property_callback(typ C.PropertyDataEnum, info *C.V8_PropertyCallbackInfo, context unsafe.Pointer) {
 	gcontext := (*Context)(context)
+	defer gcontext.engine.recoverCallbackPanic(true)
+
 	switch typ {
 	case C.OTP_Getter:
 		(*(*IndexedPropertyGetterCallback)(info.callback))(
@@ -485,6 +501,10 @@ func go_indexed_property_callback(typ C.PropertyDataEnum, info *C.V8_PropertyCal
 //export go_access_check_callback
 func go_access_check_callback(typ C.AccessCheckDataEnum, info *C.V8_AccessCheckCallbackInfo, context unsafe.Pointer ) bool {
 	gcontext := (*Context)(context)
+
+	// A panic denies the access.
+	defer gcontext.engine.recoverCallbackPanic(false)
+
 	switch typ {
 	case C.OTAC_Name:
 		(*(*NamedSecurityCallback)(info.callback))(
@@ -535,7 +555,7 @@ func (e *Engine) NewFunctionTemplate(callback FunctionCallback, data interface{}
 		callbackPtr = unsafe.Pointer(&ft.callback)
 	}
 
-	self := C.V8_NewFunctionTemplate(e.self, callbackPtr, unsafe.Pointer(&data))
+	self := C.V8_NewFunctionTemplate(e.self, callbackPtr, unsafe.Pointer(&ft.data))
 	if self == nil {
 		return nil
 	}
@@ -567,7 +587,7 @@ func (ft *FunctionTemplate) NewFunction() *Value {
 		return nil
 	}
 
-	return newValue(ft.engine, C.V8_FunctionTemplate_GetFunction(ft.self))
+	return ft.engine.newScopedValue(C.V8_FunctionTemplate_GetFunction(ft.self))
 }
 
 func (ft *FunctionTemplate) SetClassName(name string) {
@@ -594,6 +614,20 @@ func (ft *FunctionTemplate) InstanceTemplate() *ObjectTemplate {
 	return newObjectTemplate(ft.engine, self)
 }
 
+// PrototypeTemplate returns the template of the prototype object of the
+// function, the properties of which are shared by the instances.
+func (ft *FunctionTemplate) PrototypeTemplate() *ObjectTemplate {
+	ft.Lock()
+	defer ft.Unlock()
+
+	if ft.engine == nil {
+		panic("engine can't be nil")
+	}
+
+	self := C.V8_FunctionTemplate_PrototypeTemplate(ft.self)
+	return newObjectTemplate(ft.engine, self)
+}
+
 func (ft *FunctionTemplate) SetHiddenPrototype(val bool){
 	ft.Lock()
 	defer ft.Unlock()
@@ -4,8 +4,14 @@ package v8
 #include "v8_wrap.h"
 */
 import "C"
+import "context"
+import "io"
 import "unsafe"
 import "reflect"
+import "strconv"
+import "strings"
+import "unicode/utf16"
+import "unicode/utf8"
 
 var (
 	jsonObjectBegin = []byte("{")
@@ -28,11 +34,355 @@ func (cs ContextScope) Eval(code string) *Value {
 	return nil
 }
 
+// EvalE compiles and runs the code like Eval, but returns syntax errors and
+// uncaught exceptions as *Message.
+func (cs ContextScope) EvalE(code string) (*Value, error) {
+	script, err := cs.GetEngine().CompileE([]byte(code), nil)
+	if err != nil {
+		return nil, err
+	}
+	return cs.RunE(script)
+}
+
+// EvalContext compiles and runs the code like Eval, but returns uncaught
+// exceptions as *Message and terminates the script when ctx is done.
+func (cs ContextScope) EvalContext(ctx context.Context, code string) (*Value, error) {
+	return cs.GetEngine().tryRunContext(ctx, func() *Value {
+		return cs.Eval(code)
+	})
+}
+
 func (cs ContextScope) ParseJSON(json string) *Value {
 	jsonPtr := unsafe.Pointer((*reflect.StringHeader)(unsafe.Pointer(&json)).Data)
 	return newValue(cs.GetEngine(), C.V8_ParseJSON(cs.context.self, (*C.char)(jsonPtr), C.int(len(json))))
 }
 
+// JSONReviver returns the value to use instead of a parsed value, like the
+// reviver argument of JSON.parse, undefined or nil removes the property. The
+// key of the top-level value is "".
+type JSONReviver func(key string, value *Value) *Value
+
+// JSONSyntaxError is returned by ParseJSONE for malformed JSON.
+type JSONSyntaxError struct {
+	Message string // Message of the SyntaxError, like "Unexpected token } in JSON at position 7".
+	Offset  int    // Byte offset of the error in the JSON, or -1 when it's unknown.
+
+	exception *Exception
+}
+
+func (e *JSONSyntaxError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the thrown SyntaxError.
+func (e *JSONSyntaxError) Unwrap() error {
+	return e.exception
+}
+
+// ParseJSONE parses the JSON like JSON.parse, without calling the JSON.parse
+// of the context which scripts can replace. Malformed JSON is returned as
+// *JSONSyntaxError, and an exception thrown by the reviver as *Exception.
+func (cs ContextScope) ParseJSONE(json string, reviver ...JSONReviver) (*Value, error) {
+	jsonPtr := unsafe.Pointer((*reflect.StringHeader)(unsafe.Pointer(&json)).Data)
+	return cs.parseJSON(jsonPtr, len(json), reviver)
+}
+
+// ParseJSONBytes is like ParseJSONE, without converting the JSON to a Go
+// string first.
+func (cs ContextScope) ParseJSONBytes(json []byte, reviver ...JSONReviver) (*Value, error) {
+	var jsonPtr unsafe.Pointer
+	if len(json) > 0 {
+		jsonPtr = unsafe.Pointer(&json[0])
+	}
+	return cs.parseJSON(jsonPtr, len(json), reviver)
+}
+
+func (cs ContextScope) parseJSON(jsonPtr unsafe.Pointer, length int, reviver []JSONReviver) (*Value, error) {
+	engine := cs.GetEngine()
+
+	value, err := engine.tryRunException(func() *Value {
+		return newValue(engine, C.V8_ParseJSON(cs.context.self, (*C.char)(jsonPtr), C.int(length)))
+	})
+	if err != nil {
+		exception, ok := err.(*Exception)
+		if !ok || exception.Name() != "SyntaxError" {
+			return nil, err
+		}
+		var json []byte
+		if length > 0 {
+			json = (*[1 << 30]byte)(jsonPtr)[:length:length]
+		}
+		message := exception.property("message")
+		return nil, &JSONSyntaxError{message, jsonErrorOffset(json, message), exception}
+	}
+
+	if len(reviver) == 0 || reviver[0] == nil {
+		return value, nil
+	}
+
+	return engine.tryRunException(func() *Value {
+		fn := reviver[0]
+		function := engine.NewFunction(func(info FunctionCallbackInfo) {
+			if result := fn(info.Get(0).ToString(), info.Get(1)); result != nil {
+				info.ReturnValue().Set(result)
+			}
+		}, nil)
+
+		holder := engine.NewObject().ToObject()
+		holder.SetProperty("", value)
+		return reviveJSON(function, holder, "")
+	})
+}
+
+// Calls the reviver on the value of the key of the holder after its
+// properties, like the InternalizeJSONProperty steps of JSON.parse. The
+// result is nil when the reviver throws.
+func reviveJSON(reviver *Function, holder *Object, key string) *Value {
+	value := holder.GetProperty(key)
+	if value == nil {
+		return nil
+	}
+
+	if value.IsObject() {
+		object := value.ToObject()
+
+		var keys []string
+		if value.IsArray() {
+			length := value.ToArray().Length()
+			for i := 0; i < length; i++ {
+				keys = append(keys, strconv.Itoa(i))
+			}
+		} else {
+			names := object.GetOwnPropertyNames()
+			if names == nil {
+				return nil
+			}
+			for i, length := 0, names.Length(); i < length; i++ {
+				keys = append(keys, names.GetElement(i).ToString())
+			}
+		}
+
+		for _, k := range keys {
+			element := reviveJSON(reviver, object, k)
+			if element == nil {
+				return nil
+			}
+			if element.IsUndefined() {
+				object.DeleteProperty(k)
+			} else {
+				object.SetProperty(k, element)
+			}
+		}
+	}
+
+	return reviver.Call(reviver.engine.NewString(key), value)
+}
+
+// Returns the byte offset of a JSON.parse error message, which has the
+// position in UTF-16 code units.
+func jsonErrorOffset(json []byte, message string) int {
+	if strings.HasPrefix(message, "Unexpected end of JSON input") {
+		return len(json)
+	}
+
+	i := strings.LastIndex(message, " at position ")
+	if i < 0 {
+		return -1
+	}
+	position, err := strconv.Atoi(message[i+len(" at position "):])
+	if err != nil {
+		return -1
+	}
+
+	offset := 0
+	for position > 0 && offset < len(json) {
+		r, size := utf8.DecodeRune(json[offset:])
+		if r >= 0x10000 {
+			position -= 2
+		} else {
+			position--
+		}
+		offset += size
+	}
+	return offset
+}
+
+// StringifyOptions are the options of JSON.stringify.
+type StringifyOptions struct {
+	// Indent of the nested values, the gap argument of JSON.stringify.
+	Indent string
+
+	// Replacer returns the value to stringify instead of the value of a
+	// property, or undefined to skip it. The key of the value itself is "".
+	Replacer func(key string, value *Value) *Value
+}
+
+// Stringify converts the value to JSON like JSON.stringify, so the toJSON
+// methods are called, and cyclic objects or thrown exceptions are returned
+// as errors. The result is nil when JSON.stringify returns undefined, like
+// for functions.
+func (cs ContextScope) Stringify(value *Value, options StringifyOptions) ([]byte, error) {
+	json, err := cs.stringify(value, options)
+	if err != nil || json == nil {
+		return nil, err
+	}
+	return []byte(json.ToString()), nil
+}
+
+// Returns the JSON string of the value, or nil when it has no JSON.
+func (cs ContextScope) stringify(value *Value, options StringifyOptions) (*Value, error) {
+	engine := cs.GetEngine()
+
+	json, err := engine.tryRunException(func() *Value {
+		if options.Replacer != nil {
+			replacer := engine.NewFunction(func(info FunctionCallbackInfo) {
+				if result := options.Replacer(info.Get(0).ToString(), info.Get(1)); result != nil {
+					info.ReturnValue().Set(result)
+				}
+			}, nil)
+
+			holder := engine.NewObject().ToObject()
+			holder.SetProperty("", value)
+			if value = replaceJSON(replacer, holder, "", nil); value == nil {
+				return nil
+			}
+		}
+
+		gap := options.Indent
+		gapPtr := unsafe.Pointer((*reflect.StringHeader)(unsafe.Pointer(&gap)).Data)
+		return newValue(engine, C.V8_StringifyJSON(cs.context.self, value.self, (*C.char)(gapPtr), C.int(len(gap))))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if json.IsUndefined() {
+		return nil, nil
+	}
+	return json, nil
+}
+
+// Returns the value of the key of the holder after its toJSON method and
+// the replacer, like the SerializeJSONProperty steps of JSON.stringify. The
+// objects are copied with the replaced values of their properties, so the
+// native stringify doesn't call toJSON again. The result is nil when a
+// toJSON method or the replacer throws, or the object is cyclic.
+func replaceJSON(replacer *Function, holder *Object, key string, stack []*Value) *Value {
+	engine := replacer.engine
+	keyValue := engine.NewString(key)
+
+	value := holder.GetProperty(key)
+	if value == nil {
+		return nil
+	}
+	if value.IsObject() {
+		toJSON := value.ToObject().GetProperty("toJSON")
+		if toJSON == nil {
+			return nil
+		}
+		if toJSON.IsFunction() {
+			if value = toJSON.ToFunction().callWithReceiver(value, keyValue); value == nil {
+				return nil
+			}
+		}
+	}
+	if value = replacer.callWithReceiver(holder.Value, keyValue, value); value == nil {
+		return nil
+	}
+
+	if value.IsFunction() {
+		return engine.Undefined()
+	}
+	if !value.IsObject() || value.IsNumberObject() || value.IsStringObject() || value.IsBooleanObject() {
+		return value
+	}
+
+	for _, ancestor := range stack {
+		if ancestor.StrictEquals(value) {
+			C.V8_Context_ThrowException2(engine.NewTypeError("Converting circular structure to JSON").self)
+			return nil
+		}
+	}
+	stack = append(stack, value)
+
+	object := value.ToObject()
+
+	var (
+		result *Object
+		keys   []string
+	)
+	if value.IsArray() {
+		length := value.ToArray().Length()
+		result = engine.NewArray(length).ToObject()
+		for i := 0; i < length; i++ {
+			keys = append(keys, strconv.Itoa(i))
+		}
+	} else {
+		names := object.GetOwnPropertyNames()
+		if names == nil {
+			return nil
+		}
+		result = engine.NewObject().ToObject()
+		for i, length := 0, names.Length(); i < length; i++ {
+			keys = append(keys, names.GetElement(i).ToString())
+		}
+	}
+
+	for _, k := range keys {
+		element := replaceJSON(replacer, object, k, stack)
+		if element == nil {
+			return nil
+		}
+		result.ForceSetProperty(k, element, PA_None)
+	}
+	return result.Value
+}
+
+// The UTF-16 code units of the JSON written by StringifyTo at once.
+const stringifyChunkSize = 4096
+
+// StringifyTo writes the JSON of the value to w, see Stringify. The whole
+// JSON is built in the V8 heap first, then converted to UTF-8 and written in
+// chunks, so it isn't copied to a Go string at once.
+func (cs ContextScope) StringifyTo(w io.Writer, value *Value, options StringifyOptions) error {
+	json, err := cs.stringify(value, options)
+	if err != nil || json == nil {
+		return err
+	}
+
+	var (
+		units   [stringifyChunkSize]uint16
+		buf     = make([]byte, 0, stringifyChunkSize*3)
+		encoded [utf8.UTFMax]byte
+	)
+	length := int(C.V8_String_Length(json.self))
+	for start := 0; start < length; {
+		n := int(C.V8_String_Write(json.self, (*C.uint16_t)(&units[0]), C.int(start), stringifyChunkSize))
+		if n <= 0 {
+			return io.ErrUnexpectedEOF
+		}
+		chunk := units[:n]
+
+		// A surrogate pair isn't split between two chunks.
+		if last := rune(chunk[n-1]); n > 1 && start+n < length && last >= 0xd800 && last < 0xdc00 {
+			chunk = chunk[:n-1]
+		}
+		start += len(chunk)
+
+		buf = buf[:0]
+		for _, r := range utf16.Decode(chunk) {
+			buf = append(buf, encoded[:utf8.EncodeRune(encoded[:], r)]...)
+		}
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ToJSON converts the value to JSON in Go, which doesn't follow
+// JSON.stringify for toJSON methods, undefined values, cyclic objects and
+// escaping. Use ContextScope.Stringify for JSON.stringify results.
 func ToJSON(value *Value) []byte {
 	return AppendJSON(make([]byte, 0, 1024), value)
 }
@@ -2,6 +2,8 @@ package v8
 
 import "testing"
 import "runtime"
+import "bytes"
+import "strings"
 
 func TestJSON(t *testing.T) {
 	engine.NewContext(nil).Scope(func(cs ContextScope) {
@@ -74,3 +76,212 @@ func TestJSON(t *testing.T) {
 
 	runtime.GC()
 }
+
+func TestParseJSONE(t *testing.T) {
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		value, err := cs.ParseJSONE(`{"a":[1,2]}`)
+		if err != nil || string(ToJSON(value)) != `{"a":[1,2]}` {
+			t.Fatalf("unexpected result %v %v", value, err)
+		}
+
+		for _, test := range []struct {
+			json   string
+			offset int
+		}{
+			{`{"a":1,}`, 7},
+			{`{"😀":1 2}`, 10},
+			{`[1,2`, 4},
+		} {
+			_, err := cs.ParseJSONE(test.json)
+			syntaxErr, ok := err.(*JSONSyntaxError)
+			if !ok {
+				t.Fatalf("%s: unexpected error %#v", test.json, err)
+			}
+			if syntaxErr.Offset != test.offset || syntaxErr.Message == "" {
+				t.Fatalf("%s: unexpected error %q at %d", test.json, syntaxErr.Message, syntaxErr.Offset)
+			}
+			if exception, ok := syntaxErr.Unwrap().(*Exception); !ok || exception.Name() != "SyntaxError" {
+				t.Fatalf("%s: unexpected exception %#v", test.json, syntaxErr.Unwrap())
+			}
+		}
+
+		var keys []string
+		double := func(key string, value *Value) *Value {
+			keys = append(keys, key)
+			if value.IsNumber() {
+				return engine.NewNumber(value.ToNumber() * 2)
+			}
+			if key == "skip" {
+				return engine.Undefined()
+			}
+			return value
+		}
+
+		value, err = cs.ParseJSONBytes([]byte(`{"a":1,"b":[2],"skip":"x"}`), double)
+		if err != nil || string(ToJSON(value)) != `{"a":2,"b":[4]}` {
+			t.Fatalf("unexpected result %v %v", value, err)
+		}
+		if expected := "a,0,b,skip,"; strings.Join(keys, ",") != expected {
+			t.Fatalf("unexpected keys %q", keys)
+		}
+
+		fail := func(key string, value *Value) *Value {
+			cs.ThrowException("reviver")
+			return engine.Undefined()
+		}
+		if _, err := cs.ParseJSONBytes([]byte(`[1]`), fail); err == nil {
+			t.Fatal("expected exception")
+		} else if _, ok := err.(*Exception); !ok {
+			t.Fatalf("unexpected error %#v", err)
+		}
+
+		// only malformed JSON is a JSONSyntaxError
+		syntax := func(key string, value *Value) *Value {
+			cs.ThrowError(SyntaxError, "reviver")
+			return engine.Undefined()
+		}
+		if _, err := cs.ParseJSONE(`{"a":1}`, syntax); err == nil {
+			t.Fatal("expected exception")
+		} else if exception, ok := err.(*Exception); !ok || exception.Name() != "SyntaxError" {
+			t.Fatalf("unexpected error %#v", err)
+		}
+
+		if _, err := cs.ParseJSONBytes(nil); err == nil {
+			t.Fatal("expected syntax error")
+		} else if syntaxErr, ok := err.(*JSONSyntaxError); !ok || syntaxErr.Offset != 0 {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	})
+}
+
+func TestEvalE(t *testing.T) {
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		value, err := cs.EvalE("1 + 2")
+		if err != nil || value.ToInteger() != 3 {
+			t.Fatalf("unexpected result %v %v", value, err)
+		}
+
+		if _, err := cs.EvalE("var x = ;"); err == nil {
+			t.Fatal("expected syntax error")
+		} else if msg, ok := err.(*Message); !ok || msg.Line != 1 || msg.StartColumn != 8 {
+			t.Fatalf("unexpected error %#v", err)
+		}
+
+		if _, err := cs.EvalE("throw new TypeError('bad')"); err == nil {
+			t.Fatal("expected exception")
+		} else if msg, ok := err.(*Message); !ok || msg.Message != "Uncaught TypeError: bad" {
+			t.Fatalf("unexpected error %#v", err)
+		}
+	})
+}
+
+func TestStringify(t *testing.T) {
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		value := cs.Eval(`({
+			a: [1, NaN, undefined, function() {}],
+			b: undefined,
+			c: "\u0001/😀",
+			d: {toJSON: function(key) { return "toJSON " + key }},
+			e: new Date(0)
+		})`)
+
+		skipDate := func(key string, value *Value) *Value {
+			if key == "e" {
+				return engine.Undefined()
+			}
+			return value
+		}
+
+		// the result of the replacer isn't converted by toJSON again
+		replace := func(key string, value *Value) *Value {
+			if value.IsNumber() {
+				return engine.NewNumber(value.ToNumber() * 2)
+			}
+			if key == "c" {
+				return cs.Eval(`({toJSON: function() { return "again" }, x: 1})`)
+			}
+			return value
+		}
+
+		for _, test := range []struct {
+			options  StringifyOptions
+			expected string
+		}{
+			{StringifyOptions{}, `JSON.stringify(value)`},
+			{StringifyOptions{Indent: "  "}, `JSON.stringify(value, null, "  ")`},
+			{StringifyOptions{Indent: "\t", Replacer: skipDate}, `JSON.stringify(value, function(k, v) { return k == "e" ? undefined : v }, "\t")`},
+			{StringifyOptions{Replacer: replace}, `JSON.stringify(value, function(k, v) {
+				return typeof v == "number" ? v * 2 : k == "c" ? {toJSON: function() { return "again" }, x: 1} : v
+			})`},
+			{StringifyOptions{Indent: "more than ten"}, `JSON.stringify(value, null, "more than ten")`},
+			{StringifyOptions{Indent: "\n-"}, `JSON.stringify(value, null, "\n-")`},
+		} {
+			cs.Global().SetProperty("value", value)
+			expected := cs.Eval(test.expected).ToString()
+
+			json, err := cs.Stringify(value, test.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(json) != expected {
+				t.Fatalf("json should be %s not %s", expected, json)
+			}
+		}
+
+		for _, code := range []string{`undefined`, `(function() {})`, `Symbol()`, `({toJSON: function() {}})`} {
+			if json, err := cs.Stringify(cs.Eval(code), StringifyOptions{}); err != nil || json != nil {
+				t.Fatalf("%s should be nil, not %q %v", code, json, err)
+			}
+		}
+
+		for code, expected := range map[string]string{
+			`null`:          `null`,
+			`1.5`:           `1.5`,
+			`"undefined"`:   `"undefined"`,
+			`new Number(2)`: `2`,
+		} {
+			if json, err := cs.Stringify(cs.Eval(code), StringifyOptions{Indent: "  "}); err != nil || string(json) != expected {
+				t.Fatalf("%s should be %s, not %s %v", code, expected, json, err)
+			}
+		}
+
+		var buf bytes.Buffer
+		if err := cs.StringifyTo(&buf, cs.Eval(`[1, "a"]`), StringifyOptions{}); err != nil || buf.String() != `[1,"a"]` {
+			t.Fatalf(`json should be [1,"a"] not %s %v`, buf.String(), err)
+		}
+
+		if _, err := cs.Stringify(cs.Eval(`var o = {}; o.o = o; o`), StringifyOptions{}); err == nil {
+			t.Fatal("cyclic object should be an error")
+		}
+		if _, err := cs.Stringify(cs.Eval(`o`), StringifyOptions{Replacer: skipDate}); err == nil {
+			t.Fatal("cyclic object should be an error with a replacer")
+		}
+
+		// the chunks of StringifyTo don't split the surrogate pairs
+		long := cs.Eval(`({s: new Array(5000).join("😀")})`)
+		expected, _ := cs.Stringify(long, StringifyOptions{})
+		buf.Reset()
+		if err := cs.StringifyTo(&buf, long, StringifyOptions{}); err != nil || buf.String() != string(expected) {
+			t.Fatalf("unexpected json of %d bytes %v", buf.Len(), err)
+		}
+	})
+
+	// the JSON object of the context isn't used
+	engine.NewContext(nil).Scope(func(cs ContextScope) {
+		cs.Eval(`JSON.stringify = JSON.parse = null`)
+
+		json, err := cs.Stringify(cs.Eval(`({a: 1})`), StringifyOptions{Replacer: func(key string, value *Value) *Value {
+			return value
+		}})
+		if err != nil || string(json) != `{"a":1}` {
+			t.Fatalf(`json should be {"a":1} not %s %v`, json, err)
+		}
+
+		value, err := cs.ParseJSONE(`{"a":1}`, func(key string, value *Value) *Value {
+			return value
+		})
+		if err != nil || string(ToJSON(value)) != `{"a":1}` {
+			t.Fatalf("unexpected result %v %v", value, err)
+		}
+	})
+}
@@ -125,6 +125,11 @@ func (e *Engine) NewError(message string) *Value {
 	return newValue(e, C.V8_Exception_Error(e.self, (*C.char)(msgPtr), C.int(len(message))))
 }
 
+// StrictEquals reports whether the values are equal like the === operator.
+func (v *Value) StrictEquals(other *Value) bool {
+	return C.V8_Value_StrictEquals(v.self, other.self) == 1
+}
+
 func (v *Value) ToBoolean() bool {
 	return C.V8_Value_ToBoolean(v.self) == 1
 }
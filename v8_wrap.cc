@@ -6,6 +6,7 @@
 #include "v8.h"
 #include "v8_wrap.h"
 #include "libplatform/libplatform.h"
+#include "v8-profiler.h"
 
 extern "C" {
 
@@ -59,6 +60,68 @@ using namespace v8;
 
 #define PREV_CONTEXT_SLOT 1
 #define PREV_ESCAPABLE_SLOT 2
+#define ENGINE_DATA_SLOT 3
+
+// Per isolate state, kept in ENGINE_DATA_SLOT.
+class V8_EngineData {
+public:
+	V8_EngineData(ArrayBuffer::Allocator* allocator, int stack_limit) :
+		allocator(allocator),
+		snapshot(),
+		cpu_profiler(NULL),
+		stack_limit(stack_limit),
+		run_depth(0),
+		heap_limit_reached(false) {
+	}
+
+	~V8_EngineData() {
+		delete allocator;
+		delete[] snapshot.data;
+	}
+
+	ArrayBuffer::Allocator* allocator;
+	StartupData             snapshot;
+	CpuProfiler*            cpu_profiler;
+	int                     stack_limit;
+	int                     run_depth;
+	bool                    heap_limit_reached;
+};
+
+V8_EngineData* V8_Engine_Data(Isolate* isolate) {
+	return static_cast<V8_EngineData*>(isolate->GetData(ENGINE_DATA_SLOT));
+}
+
+// Keeps the stack limit of the isolate relative to the stack of the thread
+// which enters JavaScript. Go may call in from any thread, so the limit is
+// reset on every outermost entry. A heap limit termination which wasn't
+// taken by the previous run is cancelled there too.
+class V8_RunScope {
+public:
+	V8_RunScope(Isolate* isolate) {
+		data_ = V8_Engine_Data(isolate);
+		if (data_ == NULL)
+			return;
+		if (data_->run_depth == 0) {
+			if (data_->stack_limit > 0) {
+				uintptr_t here = reinterpret_cast<uintptr_t>(&here);
+				isolate->SetStackLimit(here - data_->stack_limit);
+			}
+			if (data_->heap_limit_reached) {
+				data_->heap_limit_reached = false;
+				isolate->CancelTerminateExecution();
+			}
+		}
+		data_->run_depth++;
+	}
+
+	~V8_RunScope() {
+		if (data_ != NULL)
+			data_->run_depth--;
+	}
+
+private:
+	V8_EngineData* data_;
+};
 
 class V8_Context {
 public:
@@ -234,11 +297,60 @@ void V8_Init() {
 /*
 engine
 */
+// Terminates the running script before the heap is exhausted, V8 aborts
+// the process when it really runs out of memory.
+void V8_HeapLimitCallback(Isolate* isolate, GCType type, GCCallbackFlags flags) {
+	V8_EngineData* data = V8_Engine_Data(isolate);
+	if (data == NULL || data->heap_limit_reached)
+		return;
+
+	HeapStatistics stats;
+	isolate->GetHeapStatistics(&stats);
+
+	if (stats.used_heap_size() >= stats.heap_size_limit() / 10 * 9) {
+		data->heap_limit_reached = true;
+		isolate->TerminateExecution();
+	}
+}
+
 void* V8_NewEngine() {
-    Isolate::CreateParams create_params;
-    create_params.array_buffer_allocator = v8::ArrayBuffer::Allocator::NewDefaultAllocator();
+	return V8_NewEngineWithOptions(NULL);
+}
+
+void* V8_NewEngineWithOptions(V8_EngineOptions* options) {
+	Isolate::CreateParams create_params;
+	create_params.array_buffer_allocator = v8::ArrayBuffer::Allocator::NewDefaultAllocator();
+
+	// The isolate deserializes every new context from the snapshot,
+	// so the engine keeps its own copy.
+	StartupData snapshot = { NULL, 0 };
+
+	int stack_limit = 0;
+	if (options != NULL) {
+		if (options->max_young_space_size > 0)
+			create_params.constraints.set_max_semi_space_size(options->max_young_space_size);
+		if (options->max_old_space_size > 0)
+			create_params.constraints.set_max_old_space_size(options->max_old_space_size);
+		if (options->code_range_size > 0)
+			create_params.constraints.set_code_range_size(options->code_range_size);
+		stack_limit = options->stack_limit;
+
+		if (options->snapshot_size > 0) {
+			char* data = new char[options->snapshot_size];
+			memcpy(data, options->snapshot_data, options->snapshot_size);
+			snapshot.data = data;
+			snapshot.raw_size = options->snapshot_size;
+			create_params.snapshot_blob = &snapshot;
+		}
+	}
+
 	ISOLATE_SCOPE(Isolate::New(create_params));
 
+	V8_EngineData* data = new V8_EngineData(create_params.array_buffer_allocator, stack_limit);
+	data->snapshot = snapshot;
+	isolate->SetData(ENGINE_DATA_SLOT, data);
+	isolate->AddGCEpilogueCallback(V8_HeapLimitCallback);
+
 	HandleScope handle_scope(isolate);
 	Handle<Context> context = Context::New(isolate);
 
@@ -270,20 +382,147 @@ Isolate* DisposeEngineStep1(V8_Context* the_engine) {
 void V8_DisposeEngine(void* engine) {
 	V8_Context* the_engine = static_cast<V8_Context*>(engine);
 	Isolate* isolate = DisposeEngineStep1(the_engine);
+	V8_EngineData* data = V8_Engine_Data(isolate);
+	if (data->cpu_profiler != NULL)
+		data->cpu_profiler->Dispose();
 	isolate->Dispose();
+	delete data;
+}
+
+int V8_Engine_TakeHeapLimitReached(void* engine) {
+	ENGINE_SCOPE(engine);
+
+	V8_EngineData* data = V8_Engine_Data(isolate);
+	if (data == NULL || !data->heap_limit_reached)
+		return 0;
+
+	data->heap_limit_reached = false;
+	isolate->CancelTerminateExecution();
+	return 1;
+}
+
+// No locker here, this is called from another thread while the isolate
+// is busy running a script.
+void V8_Engine_TerminateExecution(void* engine) {
+	V8_Context* the_engine = static_cast<V8_Context*>(engine);
+	the_engine->GetIsolate()->TerminateExecution();
+}
+
+void V8_Engine_CancelTerminateExecution(void* engine) {
+	ENGINE_SCOPE(engine);
+	isolate->CancelTerminateExecution();
+}
+
+int V8_Engine_IsExecutionTerminating(void* engine) {
+	ENGINE_SCOPE(engine);
+	return isolate->IsExecutionTerminating();
+}
+
+void V8_Engine_GetHeapStatistics(void* engine, V8_HeapStatistics* stats) {
+	ENGINE_SCOPE(engine);
+
+	HeapStatistics heap_stats;
+	isolate->GetHeapStatistics(&heap_stats);
+
+	stats->total_heap_size = heap_stats.total_heap_size();
+	stats->total_heap_size_executable = heap_stats.total_heap_size_executable();
+	stats->total_physical_size = heap_stats.total_physical_size();
+	stats->total_available_size = heap_stats.total_available_size();
+	stats->used_heap_size = heap_stats.used_heap_size();
+	stats->heap_size_limit = heap_stats.heap_size_limit();
+	stats->malloced_memory = heap_stats.malloced_memory();
+	stats->peak_malloced_memory = heap_stats.peak_malloced_memory();
+
+	// Adjusting by zero returns the current amount of external memory.
+	stats->external_memory = isolate->AdjustAmountOfExternalAllocatedMemory(0);
+}
+
+int V8_Engine_NumberOfHeapSpaces(void* engine) {
+	ENGINE_SCOPE(engine);
+	return isolate->NumberOfHeapSpaces();
+}
+
+int V8_Engine_GetHeapSpaceStatistics(void* engine, V8_HeapSpaceStatistics* stats, int index) {
+	ENGINE_SCOPE(engine);
+
+	HeapSpaceStatistics space_stats;
+	if (!isolate->GetHeapSpaceStatistics(&space_stats, index))
+		return 0;
+
+	stats->space_name = space_stats.space_name();
+	stats->space_size = space_stats.space_size();
+	stats->space_used_size = space_stats.space_used_size();
+	stats->space_available_size = space_stats.space_available_size();
+	stats->physical_space_size = space_stats.physical_space_size();
+	return 1;
 }
 
 void* V8_ParseJSON(void* context, const char* json, int json_length) {
 	CONTEXT_SCOPE(context);
 
-	Handle<Value> value = JSON::Parse(
-		String::NewFromUtf8(isolate, json, String::kInternalizedString, json_length)
+	HandleScope handle_scope(isolate);
+
+	Local<Context> local_context = Local<Context>::New(isolate, the_context->self);
+	Context::Scope context_scope(local_context);
+
+	MaybeLocal<Value> value = JSON::Parse(
+		local_context,
+		String::NewFromUtf8(isolate, json, String::kNormalString, json_length)
 	);
 
 	if (value.IsEmpty())
 		return NULL;
 
-	return new_V8_Value(the_context, value);
+	return new_V8_Value(the_context, value.ToLocalChecked());
+}
+
+void* V8_StringifyJSON(void* context, void* value, const char* gap, int gap_length) {
+	CONTEXT_SCOPE(context);
+
+	HandleScope handle_scope(isolate);
+
+	Local<Context> local_context = Local<Context>::New(isolate, the_context->self);
+	Context::Scope context_scope(local_context);
+
+	Local<Value> local_value = Local<Value>::New(isolate, static_cast<V8_Value*>(value)->self);
+
+	if (local_value->IsObject()) {
+		MaybeLocal<String> json = JSON::Stringify(
+			local_context,
+			local_value.As<Object>(),
+			String::NewFromUtf8(isolate, gap, String::kNormalString, gap_length)
+		);
+
+		if (json.IsEmpty())
+			return NULL;
+
+		// The objects without JSON, like functions, are stringified to
+		// "undefined", which no JSON is.
+		Local<String> result = json.ToLocalChecked();
+		if (result->StrictEquals(String::NewFromUtf8(isolate, "undefined")))
+			return new_V8_Value(the_context, Undefined(isolate));
+
+		return new_V8_Value(the_context, result);
+	}
+
+	// JSON::Stringify takes an object, so a primitive is stringified as the
+	// property of {"": value}, which is a single line without a gap, and {}
+	// when the value has no JSON, like undefined.
+	Local<Object> holder = Object::New(isolate);
+	if (holder->CreateDataProperty(local_context, String::Empty(isolate), local_value).IsNothing())
+		return NULL;
+
+	MaybeLocal<String> json = JSON::Stringify(local_context, holder);
+	if (json.IsEmpty())
+		return NULL;
+
+	String::Value holder_json(json.ToLocalChecked());
+	if (holder_json.length() <= 5)
+		return new_V8_Value(the_context, Undefined(isolate));
+
+	return new_V8_Value(the_context,
+		String::NewFromTwoByte(isolate, *holder_json + 4, String::kNormalString, holder_json.length() - 5)
+	);
 }
 
 void V8_ForceGC(void* engine) {
@@ -294,6 +533,166 @@ void V8_ForceGC(void* engine) {
 }
 
 
+/*
+profiler
+*/
+CpuProfiler* V8_Engine_CpuProfiler(Isolate* isolate) {
+	V8_EngineData* data = V8_Engine_Data(isolate);
+	if (data->cpu_profiler == NULL)
+		data->cpu_profiler = CpuProfiler::New(isolate);
+	return data->cpu_profiler;
+}
+
+void V8_Engine_SetCpuProfilerSamplingInterval(void* engine, int us) {
+	ENGINE_SCOPE(engine);
+	V8_Engine_CpuProfiler(isolate)->SetSamplingInterval(us);
+}
+
+void V8_Engine_StartCpuProfile(void* engine, const char* title, int title_length) {
+	ENGINE_SCOPE(engine);
+	HandleScope handle_scope(isolate);
+
+	V8_Engine_CpuProfiler(isolate)->StartProfiling(
+		String::NewFromUtf8(isolate, title, String::kNormalString, title_length)
+	);
+}
+
+void V8_WalkCpuProfileNode(void* go_profile, const CpuProfileNode* node, unsigned int parent_id) {
+	go_cpu_profile_node(
+		go_profile,
+		parent_id,
+		node->GetNodeId(),
+		(char*)node->GetFunctionNameStr(),
+		(char*)node->GetScriptResourceNameStr(),
+		node->GetScriptId(),
+		node->GetLineNumber(),
+		node->GetColumnNumber(),
+		node->GetHitCount()
+	);
+
+	for (int i = 0; i < node->GetChildrenCount(); i++) {
+		V8_WalkCpuProfileNode(go_profile, node->GetChild(i), node->GetNodeId());
+	}
+}
+
+// Stops the last started profile and copies it into go_profile.
+int V8_Engine_StopCpuProfile(void* engine, void* go_profile) {
+	ENGINE_SCOPE(engine);
+	HandleScope handle_scope(isolate);
+
+	CpuProfile* profile = V8_Engine_CpuProfiler(isolate)->StopProfiling(String::Empty(isolate));
+	if (profile == NULL)
+		return 0;
+
+	String::Utf8Value title(profile->GetTitle());
+	go_cpu_profile_header(
+		go_profile,
+		*title ? *title : (char*)"",
+		profile->GetStartTime(),
+		profile->GetEndTime()
+	);
+
+	V8_WalkCpuProfileNode(go_profile, profile->GetTopDownRoot(), 0);
+
+	profile->Delete();
+	return 1;
+}
+
+// Streams a serialized heap snapshot to a Go io.Writer.
+class V8_OutputStream : public OutputStream {
+public:
+	V8_OutputStream(void* go_writer) : go_writer_(go_writer) {
+	}
+
+	virtual void EndOfStream() {
+	}
+
+	virtual int GetChunkSize() {
+		return 64 * 1024;
+	}
+
+	virtual WriteResult WriteAsciiChunk(char* data, int size) {
+		if (go_heap_snapshot_write(go_writer_, data, size) == 0)
+			return kAbort;
+		return kContinue;
+	}
+
+private:
+	void* go_writer_;
+};
+
+int V8_Engine_TakeHeapSnapshot(void* engine, void* go_writer) {
+	ENGINE_SCOPE(engine);
+	HandleScope handle_scope(isolate);
+
+	const HeapSnapshot* snapshot = isolate->GetHeapProfiler()->TakeHeapSnapshot();
+	if (snapshot == NULL)
+		return 0;
+
+	V8_OutputStream stream(go_writer);
+	snapshot->Serialize(&stream, HeapSnapshot::kJSON);
+
+	const_cast<HeapSnapshot*>(snapshot)->Delete();
+	return 1;
+}
+
+int V8_Engine_StartSamplingHeapProfiler(void* engine, uint64_t interval, int stack_depth) {
+	ENGINE_SCOPE(engine);
+	return isolate->GetHeapProfiler()->StartSamplingHeapProfiler(interval, stack_depth);
+}
+
+void V8_Engine_StopSamplingHeapProfiler(void* engine) {
+	ENGINE_SCOPE(engine);
+	isolate->GetHeapProfiler()->StopSamplingHeapProfiler();
+}
+
+void V8_WalkAllocationProfileNode(void* go_profile, AllocationProfile::Node* node, unsigned int parent_id, unsigned int* next_id) {
+	unsigned int id = (*next_id)++;
+
+	String::Utf8Value name(node->name);
+	String::Utf8Value script_name(node->script_name);
+
+	go_allocation_profile_node(
+		go_profile,
+		parent_id,
+		id,
+		*name ? *name : (char*)"",
+		*script_name ? *script_name : (char*)"",
+		node->script_id,
+		node->line_number,
+		node->column_number
+	);
+
+	for (size_t i = 0; i < node->allocations.size(); i++) {
+		go_allocation_profile_allocation(
+			go_profile,
+			id,
+			node->allocations[i].size,
+			node->allocations[i].count
+		);
+	}
+
+	for (size_t i = 0; i < node->children.size(); i++) {
+		V8_WalkAllocationProfileNode(go_profile, node->children[i], id, next_id);
+	}
+}
+
+// Copies the current allocation profile into go_profile.
+int V8_Engine_GetAllocationProfile(void* engine, void* go_profile) {
+	ENGINE_SCOPE(engine);
+	HandleScope handle_scope(isolate);
+
+	AllocationProfile* profile = isolate->GetHeapProfiler()->GetAllocationProfile();
+	if (profile == NULL)
+		return 0;
+
+	unsigned int next_id = 1;
+	V8_WalkAllocationProfileNode(go_profile, profile->GetRootNode(), 0, &next_id);
+
+	delete profile;
+	return 1;
+}
+
 /*
 context
 */
@@ -381,18 +780,27 @@ void* V8_Value_Escape(void* context, void* cscapeValue){
 	return new_V8_Value(ctx, local);
 }
 
+// Returns the context of the current context scope, or NULL without one.
+// The callers return NULL too, and the Go side raises the panic, which
+// must not unwind the C++ frames.
 V8_Context* V8_Current_Context(Isolate* isolate) {
 	void* data = isolate->GetData(PREV_CONTEXT_SLOT);
 	if (data == NULL)
-		go_panic((char*)"Please call this API in a context scope");
-	return static_cast<V8_Context*>(static_cast<scope_data*>(isolate->GetData(PREV_CONTEXT_SLOT))->context);
+		return NULL;
+	return static_cast<V8_Context*>(static_cast<scope_data*>(data)->context);
 }
 
+// Returns the Go context of the current context scope, or NULL without one.
 void* V8_Current_ContextPtr(Isolate* isolate) {
 	void* data = isolate->GetData(PREV_CONTEXT_SLOT);
 	if (data == NULL)
-		go_panic((char*)"Please call this API in a context scope");
-	return static_cast<scope_data*>(isolate->GetData(PREV_CONTEXT_SLOT))->context_ptr;
+		return NULL;
+	return static_cast<scope_data*>(data)->context_ptr;
+}
+
+int V8_Engine_InContextScope(void* engine) {
+	ENGINE_SCOPE(engine);
+	return isolate->GetData(PREV_CONTEXT_SLOT) != NULL;
 }
 
 void* V8_Context_Global(void* context) {
@@ -401,6 +809,132 @@ void* V8_Context_Global(void* context) {
 	return new_V8_Value(the_context, local_context->Global());
 }
 
+// Writes the host objects, like the bound Go objects, by the Go engine.
+class V8_SerializerDelegate : public ValueSerializer::Delegate {
+public:
+	V8_SerializerDelegate(V8_Context* context, void* go_engine) :
+		serializer(NULL), context_(context), go_engine_(go_engine) {
+	}
+
+	virtual void ThrowDataCloneError(Local<String> message) {
+		context_->GetIsolate()->ThrowException(Exception::Error(message));
+	}
+
+	virtual Maybe<bool> WriteHostObject(Isolate* isolate, Local<Object> object) {
+		char* data = NULL;
+		int size = 0;
+
+		// The Go serializer throws the errors.
+		TryCatch try_catch(isolate);
+		go_serializer_write_host_object(go_engine_, new_V8_Value(context_, object), &data, &size);
+		if (try_catch.HasCaught()) {
+			try_catch.ReThrow();
+			return Nothing<bool>();
+		}
+
+		serializer->WriteUint32(size);
+		serializer->WriteRawBytes(data, size);
+		free(data);
+		return Just(true);
+	}
+
+	ValueSerializer* serializer;
+
+private:
+	V8_Context* context_;
+	void*       go_engine_;
+};
+
+// Reads the host objects written by V8_SerializerDelegate.
+class V8_DeserializerDelegate : public ValueDeserializer::Delegate {
+public:
+	V8_DeserializerDelegate(void* go_deserializer) :
+		deserializer(NULL), go_deserializer_(go_deserializer) {
+	}
+
+	virtual MaybeLocal<Object> ReadHostObject(Isolate* isolate) {
+		uint32_t size = 0;
+		const void* data = NULL;
+
+		if (!deserializer->ReadUint32(&size) || !deserializer->ReadRawBytes(size, &data)) {
+			isolate->ThrowException(Exception::Error(
+				String::NewFromUtf8(isolate, "Unable to deserialize host object")
+			));
+			return MaybeLocal<Object>();
+		}
+
+		// The Go deserializer throws the errors.
+		TryCatch try_catch(isolate);
+		void* object = go_deserializer_read_host_object(go_deserializer_, (char*)data, size);
+		if (try_catch.HasCaught() || object == NULL) {
+			try_catch.ReThrow();
+			return MaybeLocal<Object>();
+		}
+
+		return Local<Value>::New(isolate, static_cast<V8_Value*>(object)->self).As<Object>();
+	}
+
+	ValueDeserializer* deserializer;
+
+private:
+	void* go_deserializer_;
+};
+
+// Serializes the value in the current context, the result is allocated by
+// malloc. Returns NULL when an exception was thrown.
+void* V8_Serialize(void* engine, void* value, void* go_engine, int* size) {
+	ENGINE_SCOPE(engine);
+	HandleScope handle_scope(isolate);
+
+	V8_Context* the_context = V8_Current_Context(isolate);
+	if (the_context == NULL)
+		return NULL;
+
+	Local<Context> local_context = Local<Context>::New(isolate, the_context->self);
+	Context::Scope context_scope(local_context);
+
+	V8_SerializerDelegate delegate(the_context, go_engine);
+	ValueSerializer serializer(isolate, &delegate);
+	delegate.serializer = &serializer;
+
+	serializer.WriteHeader();
+
+	Local<Value> local_value = Local<Value>::New(isolate, static_cast<V8_Value*>(value)->self);
+	if (serializer.WriteValue(local_context, local_value).IsNothing())
+		return NULL;
+
+	std::pair<uint8_t*, size_t> buffer = serializer.Release();
+	*size = (int)buffer.second;
+	return buffer.first;
+}
+
+// Deserializes a value in the current context. Returns NULL when an
+// exception was thrown.
+void* V8_Deserialize(void* engine, const char* data, int size, void* go_deserializer) {
+	ENGINE_SCOPE(engine);
+	HandleScope handle_scope(isolate);
+
+	V8_Context* the_context = V8_Current_Context(isolate);
+	if (the_context == NULL)
+		return NULL;
+
+	Local<Context> local_context = Local<Context>::New(isolate, the_context->self);
+	Context::Scope context_scope(local_context);
+
+	V8_DeserializerDelegate delegate(go_deserializer);
+	ValueDeserializer deserializer(isolate, (const uint8_t*)data, size, &delegate);
+	delegate.deserializer = &deserializer;
+
+	if (deserializer.ReadHeader(local_context).IsNothing())
+		return NULL;
+
+	MaybeLocal<Value> value = deserializer.ReadValue(local_context);
+	if (value.IsEmpty())
+		return NULL;
+
+	return new_V8_Value(the_context, value.ToLocalChecked());
+}
+
 // Extracts a C string from a V8 Utf8Value.
 const char* ToCString(const String::Utf8Value& value) {
   return *value ? *value : "<string conversion failed>";
@@ -413,13 +947,18 @@ char* CopyString(const String::Utf8Value& value) {
 	return cstr;
 }
 
+// Throws an Error, the exception is scheduled and thrown when the callback
+// returns to JavaScript.
 void V8_Context_ThrowException(void* context, const char* err, int err_length) {
-	V8_Context* ctx = static_cast<V8_Context*>(context);
-	ISOLATE_SCOPE(ctx->GetIsolate());
+	CONTEXT_SCOPE(context);
+	HandleScope handle_scope(isolate);
 
-	isolate->ThrowException(
-		String::NewFromUtf8(isolate, err, String::kInternalizedString, err_length)
-	);
+	Local<Context> local_context = Local<Context>::New(isolate, the_context->self);
+	Context::Scope context_scope(local_context);
+
+	isolate->ThrowException(Exception::Error(
+		String::NewFromUtf8(isolate, err, String::kNormalString, err_length)
+	));
 }
 
 void V8_Context_ThrowException2(void* value) {
@@ -479,6 +1018,9 @@ void* V8_Context_TryCatch(void* context, void* callback) {
 	V8_Context* ctx = static_cast<V8_Context*>(context);
 	ISOLATE_SCOPE(ctx->GetIsolate());
 
+	// The runs of the callback are taken as one, so a heap limit reached
+	// by any of them is left for tryRun to take.
+	V8_RunScope run_scope(isolate);
 	TryCatch try_catch;
 
 	go_try_catch_callback(callback);
@@ -511,6 +1053,7 @@ void* V8_Context_TryCatchException(void* context, void* callback) {
 	V8_Context* ctx = static_cast<V8_Context*>(context);
 	ISOLATE_SCOPE(ctx->GetIsolate());
 
+	V8_RunScope run_scope(isolate);
 	TryCatch try_catch;
 
 	go_try_catch_callback(callback);
@@ -521,9 +1064,10 @@ void* V8_Context_TryCatchException(void* context, void* callback) {
 
 	String::Utf8Value exception(try_catch.Exception());
 	Handle<Message> message = try_catch.Message();
+	void* go_message;
 
 	if (message.IsEmpty()) {
-		return go_make_message(
+		go_message = go_make_message(
 			CopyString(exception),
 			NULL,
 			NULL,
@@ -534,9 +1078,54 @@ void* V8_Context_TryCatchException(void* context, void* callback) {
 			0,
 			0
 		);
+	} else {
+		go_message = V8_Make_Message(message);
 	}
 
-	return go_make_exception(new_V8_Value(ctx, try_catch.Exception()), V8_Make_Message(message));
+	return go_make_exception(new_V8_Value(ctx, try_catch.Exception()), go_message);
+}
+
+// Runs the scripts in a new context and serializes the heap into blob.
+// Returns the message of the first script which throws.
+void* V8_CreateSnapshot(const char** scripts, int* script_lengths, int count, V8_StartupData* blob) {
+	SnapshotCreator creator;
+	Isolate* isolate = creator.GetIsolate();
+
+	{
+		Locker locker(isolate);
+		HandleScope handle_scope(isolate);
+		Local<Context> context = Context::New(isolate);
+		Context::Scope context_scope(context);
+
+		for (int i = 0; i < count; i++) {
+			TryCatch try_catch(isolate);
+
+			Local<String> source = String::NewFromUtf8(isolate, scripts[i], String::kNormalString, script_lengths[i]);
+			Local<Script> script;
+
+			if (!Script::Compile(context, source).ToLocal(&script) || script->Run(context).IsEmpty()) {
+				String::Utf8Value exception(try_catch.Exception());
+				Handle<Message> message = try_catch.Message();
+
+				if (message.IsEmpty()) {
+					return go_make_message(CopyString(exception), NULL, NULL, NULL, 0, 0, 0, 0, 0);
+				}
+
+				return V8_Make_Message(message);
+			}
+		}
+
+		creator.SetDefaultContext(context);
+	}
+
+	StartupData data = creator.CreateBlob(SnapshotCreator::FunctionCodeHandling::kClear);
+	blob->data = data.data;
+	blob->size = data.raw_size;
+	return NULL;
+}
+
+void V8_DeleteSnapshot(V8_StartupData* blob) {
+	delete[] blob->data;
 }
 
 void V8_Context_SetSecurityToken(void* context, void* value){
@@ -674,8 +1263,11 @@ void* V8_Script_Run(void* script) {
 	V8_Script* the_script = static_cast<V8_Script*>(script);
 	ISOLATE_SCOPE(the_script->engine->GetIsolate());
 	V8_Context* the_context = V8_Current_Context(isolate);
+	if (the_context == NULL)
+		return NULL;
 	Local<UnboundScript> local_unbound_script = Local<UnboundScript>::New(isolate, the_script->self);
 	Local<Script> local_script = local_unbound_script->BindToCurrentContext();
+	V8_RunScope run_scope(isolate);
 	return new_V8_Value(the_context, local_script->Run());
 }
 
@@ -788,6 +1380,11 @@ int V8_Value_IsRegExp(void* value) {
 	return local_value->IsRegExp();
 }
 
+int V8_Value_StrictEquals(void* value, void* other) {
+	VALUE_SCOPE(value);
+	return local_value->StrictEquals(Local<Value>::New(isolate, static_cast<V8_Value*>(other)->self));
+}
+
 int V8_Value_ToBoolean(void* value) {
 	VALUE_SCOPE(value);
 	return local_value->BooleanValue();
@@ -813,6 +1410,18 @@ int32_t V8_Value_ToInt32(void* value) {
 	return local_value->Int32Value();
 }
 
+int V8_String_Length(void* value) {
+	VALUE_SCOPE(value);
+	return Local<String>::Cast(local_value)->Length();
+}
+
+// Writes the UTF-16 code units of the string from start to the buffer, and
+// returns the count.
+int V8_String_Write(void* value, uint16_t* buffer, int start, int length) {
+	VALUE_SCOPE(value);
+	return Local<String>::Cast(local_value)->Write(buffer, start, length, String::NO_NULL_TERMINATION);
+}
+
 char* V8_Value_ToString(void* value) {
 	VALUE_SCOPE(value);
 
@@ -967,7 +1576,11 @@ void* V8_Object_GetAlignedPointerFromInternalField(void* value, int index) {
 
 void* V8_Object_GetRealNamedProperty(void* value, const char* key){
 	VALUE_SCOPE(value);
-	return new_V8_Value(V8_Current_Context(isolate),
+	V8_Context* the_context = V8_Current_Context(isolate);
+	if (the_context == NULL)
+		return NULL;
+
+	return new_V8_Value(the_context,
 		Local<Object>::Cast(local_value)->GetRealNamedProperty(
 			String::NewFromUtf8(isolate, key, String::kInternalizedString)
 		)
@@ -999,7 +1612,11 @@ int V8_Object_SetProperty(void* value, const char* key, int key_length, void* pr
 void* V8_Object_GetProperty(void* value, const char* key, int key_length) {
 	VALUE_SCOPE(value);
 
-	return new_V8_Value(V8_Current_Context(isolate),
+	V8_Context* the_context = V8_Current_Context(isolate);
+	if (the_context == NULL)
+		return NULL;
+
+	return new_V8_Value(the_context,
 		Local<Object>::Cast(local_value)->Get(
 			String::NewFromUtf8(isolate, key, String::kInternalizedString, key_length)
 		)
@@ -1018,7 +1635,11 @@ int V8_Object_SetElement(void* value, uint32_t index, void* elem_value) {
 void* V8_Object_GetElement(void* value, uint32_t index) {
 	VALUE_SCOPE(value);
 
-	return new_V8_Value(V8_Current_Context(isolate),
+	V8_Context* the_context = V8_Current_Context(isolate);
+	if (the_context == NULL)
+		return NULL;
+
+	return new_V8_Value(the_context,
 		Local<Object>::Cast(local_value)->Get(index)
 	);
 }
@@ -1081,7 +1702,11 @@ int V8_Object_DeleteElement(void* value, uint32_t index) {
 void* V8_Object_GetPropertyNames(void* value) {
 	VALUE_SCOPE(value);
 
-	return new_V8_Value(V8_Current_Context(isolate),
+	V8_Context* the_context = V8_Current_Context(isolate);
+	if (the_context == NULL)
+		return NULL;
+
+	return new_V8_Value(the_context,
 		Local<Object>::Cast(local_value)->GetPropertyNames()
 	);
 }
@@ -1089,7 +1714,11 @@ void* V8_Object_GetPropertyNames(void* value) {
 void* V8_Object_GetOwnPropertyNames(void* value) {
 	VALUE_SCOPE(value);
 
-	return new_V8_Value(V8_Current_Context(isolate),
+	V8_Context* the_context = V8_Current_Context(isolate);
+	if (the_context == NULL)
+		return NULL;
+
+	return new_V8_Value(the_context,
 		Local<Object>::Cast(local_value)->GetOwnPropertyNames()
 	);
 }
@@ -1097,7 +1726,11 @@ void* V8_Object_GetOwnPropertyNames(void* value) {
 void* V8_Object_GetPrototype(void* value) {
 	VALUE_SCOPE(value);
 
-	return new_V8_Value(V8_Current_Context(isolate),
+	V8_Context* the_context = V8_Current_Context(isolate);
+	if (the_context == NULL)
+		return NULL;
+
+	return new_V8_Value(the_context,
 		Local<Object>::Cast(local_value)->GetPrototype()
 	);
 }
@@ -1112,7 +1745,11 @@ int V8_Object_SetPrototype(void* value, void* proto) {
 
 void* V8_Object_GetConstructorName(void* value){
 	VALUE_SCOPE(value);
-	return new_V8_Value(V8_Current_Context(isolate),
+	V8_Context* the_context = V8_Current_Context(isolate);
+	if (the_context == NULL)
+		return NULL;
+
+	return new_V8_Value(the_context,
 		Local<Object>::Cast(local_value)->GetConstructorName()
 	);
 }
@@ -1139,6 +1776,8 @@ void V8_AccessorGetterCallback(Local<String> property, const PropertyCallbackInf
 	callback_info.key_length = Local<Integer>::Cast(callback_data->Get(OTA_KeyLength))->Value();
 
 	void* context_ptr = V8_Current_ContextPtr(isolate);
+	if (context_ptr == NULL)
+		return;
 
 	go_accessor_callback(OTA_Getter, &callback_info, context_ptr);
 
@@ -1150,6 +1789,10 @@ void V8_AccessorSetterCallback(Local<String> property, Local<Value> value, const
 	Isolate* isolate_ptr = info.GetIsolate();
 	ISOLATE_SCOPE(isolate_ptr);
 
+	void* context_ptr = V8_Current_ContextPtr(isolate);
+	if (context_ptr == NULL)
+		return;
+
 	Local<Array> callback_data = Local<Array>::Cast(info.Data());
 
 	V8_AccessorCallbackInfo callback_info;
@@ -1162,8 +1805,6 @@ void V8_AccessorSetterCallback(Local<String> property, Local<Value> value, const
 	callback_info.key = (const char*)Local<External>::Cast(callback_data->Get(OTA_KeyString))->Value();
 	callback_info.key_length = Local<Integer>::Cast(callback_data->Get(OTA_KeyLength))->Value();
 
-	void* context_ptr = V8_Current_ContextPtr(isolate);
-
 	go_accessor_callback(OTA_Setter, &callback_info, context_ptr);
 
 	if (callback_info.returnValue != NULL)
@@ -1180,8 +1821,12 @@ void V8_Object_SetAccessor(void *value, const char* key, int key_length, void* g
 	// take place there                                              
 	Context::Scope context_scope(local_context);  
 
+	V8_Context* the_context = V8_Current_Context(isolate);
+	if (the_context == NULL)
+		return;
+
 	Handle<Array> callback_info = Array::New(isolate, OTA_Num);
-	callback_info->Set(OTA_Context, External::New(isolate, (void*)V8_Current_Context(isolate)));
+	callback_info->Set(OTA_Context, External::New(isolate, (void*)the_context));
 	callback_info->Set(OTA_Getter, External::New(isolate, getter));
 	callback_info->Set(OTA_Setter, External::New(isolate, setter));
 	callback_info->Set(OTA_KeyString, External::New(isolate, (void*)key));
@@ -1492,6 +2137,8 @@ void V8_FunctionCallback(const v8::FunctionCallbackInfo<Value>& info) {
 	void* data = Local<External>::Cast(callback_data->Get(2))->Value();
 
 	void* context_ptr = V8_Current_ContextPtr(isolate);
+	if (context_ptr == NULL)
+		return;
 
 	go_function_callback(&callback_info, callback, context_ptr, data);
 
@@ -1511,14 +2158,26 @@ void* V8_NewFunction(void* engine, void* callback, void* data) {
 	callback_data->Set(1, External::New(isolate, callback));
 	callback_data->Set(2, External::New(isolate, data));
 
-	return new_V8_Value(V8_Current_Context(isolate),
+	V8_Context* the_context = V8_Current_Context(isolate);
+	if (the_context == NULL)
+		return NULL;
+
+	return new_V8_Value(the_context,
 		Function::New(isolate, V8_FunctionCallback, callback_data)
 	);
 }
 
 void* V8_Function_Call(void* value, int argc, void* argv) {
+	return V8_Function_CallWithReceiver(value, value, argc, argv);
+}
+
+void* V8_Function_CallWithReceiver(void* value, void* recv, int argc, void* argv) {
 	VALUE_SCOPE(value);
 
+	V8_Context* the_context = V8_Current_Context(isolate);
+	if (the_context == NULL)
+		return NULL;
+
 	Handle<Value>* real_argv = new Handle<Value>[argc];
 	V8_Value* *argv_ptr = (V8_Value**)argv;
 
@@ -1526,8 +2185,11 @@ void* V8_Function_Call(void* value, int argc, void* argv) {
 		real_argv[i] = Local<Value>::New(isolate, static_cast<V8_Value*>(argv_ptr[i])->self);
 	}
 
-	void* result = new_V8_Value(V8_Current_Context(isolate),
-		Local<Function>::Cast(local_value)->Call(local_value, argc, real_argv)
+	V8_RunScope run_scope(isolate);
+	void* result = new_V8_Value(the_context,
+		Local<Function>::Cast(local_value)->Call(
+			Local<Value>::New(isolate, static_cast<V8_Value*>(recv)->self), argc, real_argv
+		)
 	);
 
 	delete[] real_argv;
@@ -1538,6 +2200,10 @@ void* V8_Function_Call(void* value, int argc, void* argv) {
 void* V8_Function_NewInstance(void* value, int argc, void* argv) {
 	VALUE_SCOPE(value);
 
+	V8_Context* the_context = V8_Current_Context(isolate);
+	if (the_context == NULL)
+		return NULL;
+
 	Handle<Value>* real_argv = new Handle<Value>[argc];
 	V8_Value* *argv_ptr = (V8_Value**)argv;
 
@@ -1545,7 +2211,8 @@ void* V8_Function_NewInstance(void* value, int argc, void* argv) {
 		real_argv[i] = Local<Value>::New(isolate, static_cast<V8_Value*>(argv_ptr[i])->self);
 	}
 
-	void* result = new_V8_Value(V8_Current_Context(isolate),
+	V8_RunScope run_scope(isolate);
+	void* result = new_V8_Value(the_context,
 		Local<Function>::Cast(local_value)->NewInstance(argc, real_argv)
 	);
 
@@ -1572,6 +2239,12 @@ void* V8_FunctionCallbackInfo_Callee(void* info) {
 	return new_V8_Value(the_info->engine, the_info->info->Callee());
 }
 
+void* V8_FunctionCallbackInfo_NewTarget(void* info) {
+	V8_FunctionCallbackInfo* the_info = (V8_FunctionCallbackInfo*)info;
+	ENGINE_SCOPE(the_info->engine);
+	return new_V8_Value(the_info->engine, the_info->info->NewTarget());
+}
+
 void* V8_FunctionCallbackInfo_This(void* info) {
 	V8_FunctionCallbackInfo* the_info = (V8_FunctionCallbackInfo*)info;
 	ENGINE_SCOPE(the_info->engine);
@@ -1639,6 +2312,16 @@ void V8_ObjectTemplate_SetProperty(void* tpl, const char* key, int key_length, v
 	);
 }
 
+void V8_ObjectTemplate_SetFunctionTemplate(void* tpl, const char* key, int key_length, void* func_tpl, int attribs) {
+	OBJECT_TEMPLATE_HANDLE_SCOPE(tpl);
+
+	local_template->Set(
+		String::NewFromUtf8(isolate, key, String::kInternalizedString, key_length),
+		Local<FunctionTemplate>::New(isolate, static_cast<V8_FunctionTemplate*>(func_tpl)->self),
+		(PropertyAttribute)attribs
+	);
+}
+
 void* V8_ObjectTemplate_NewInstance(void* engine, void* tpl) {
 	OBJECT_TEMPLATE_SCOPE(tpl);
 	V8_Context* the_engine = static_cast<V8_Context*>(engine);
@@ -1683,6 +2366,11 @@ void V8_NamedPropertyGetterCallbackBase(
 	Local<Value> callback_data_val
 ) {
     ISOLATE_SCOPE(isolate_ptr);
+
+	void* context_ptr = V8_Current_ContextPtr(isolate);
+	if (context_ptr == NULL)
+		return;
+
     Local<Array> callback_data = Local<Array>::Cast(callback_data_val);
     V8_PropertyCallbackInfo callback_info;
     callback_info.engine = Local<External>::Cast(callback_data->Get(OTP_Context))->Value();
@@ -1705,8 +2393,6 @@ void V8_NamedPropertyGetterCallbackBase(
 		);
 	}
 
-	void* context_ptr = V8_Current_ContextPtr(isolate);
-
 	go_named_property_callback(typ, &callback_info, context_ptr);
 
 	if (typ != OTP_Enumerator) {
@@ -1780,6 +2466,10 @@ void V8_IndexedPropertyGetterCallbackBase(
 ) {
     ISOLATE_SCOPE(isolate_ptr);
 
+	void* context_ptr = V8_Current_ContextPtr(isolate);
+	if (context_ptr == NULL)
+		return;
+
     Local<Array> callback_data = Local<Array>::Cast(callback_data_val);
     V8_PropertyCallbackInfo callback_info;
     callback_info.engine = Local<External>::Cast(callback_data->Get(OTP_Context))->Value();
@@ -1796,8 +2486,6 @@ void V8_IndexedPropertyGetterCallbackBase(
 		);
 	}
 
-	void* context_ptr = V8_Current_ContextPtr(isolate);
-
 	go_indexed_property_callback(typ, &callback_info, context_ptr);
 
 	if (callback_info.returnValue != NULL)
@@ -1813,15 +2501,15 @@ void V8_IndexedPropertySetterCallback(uint32_t index, Local<Value> value, const
 }
 
 void V8_IndexedPropertyDeleterCallback(uint32_t index, const PropertyCallbackInfo<Boolean> &info) {
-	V8_IndexedPropertyGetterCallbackBase(OTP_Getter, index, Local<Value>(), (void*)&info, info.GetIsolate(), info.Data());
+	V8_IndexedPropertyGetterCallbackBase(OTP_Deleter, index, Local<Value>(), (void*)&info, info.GetIsolate(), info.Data());
 }
 
 void V8_IndexedPropertyQueryCallback(uint32_t index, const PropertyCallbackInfo<Integer> &info) {
-	V8_IndexedPropertyGetterCallbackBase(OTP_Getter, index, Local<Value>(), (void*)&info, info.GetIsolate(), info.Data());
+	V8_IndexedPropertyGetterCallbackBase(OTP_Query, index, Local<Value>(), (void*)&info, info.GetIsolate(), info.Data());
 }
 
 void V8_IndexedPropertyEnumeratorCallback(const PropertyCallbackInfo<Array> &info) {
-	V8_IndexedPropertyGetterCallbackBase(OTP_Getter, 0, Local<Value>(), (void*)&info, info.GetIsolate(), info.Data());
+	V8_IndexedPropertyGetterCallbackBase(OTP_Enumerator, 0, Local<Value>(), (void*)&info, info.GetIsolate(), info.Data());
 }
 
 void V8_ObjectTemplate_SetIndexedPropertyHandler(
@@ -1875,6 +2563,11 @@ bool V8_AccesCheckCallback(
 	Local<Array> callback_data = Local<Array>::Cast(data);
 	void* engine = Local<External>::Cast(callback_data->Get(OTAC_Context))->Value();
 	ENGINE_SCOPE(engine);
+
+	void* context_ptr = V8_Current_ContextPtr(isolate);
+	if (context_ptr == NULL)
+		return false;
+
 	V8_AccessCheckCallbackInfo callback_info;
 	callback_info.engine = engine;
 	callback_info.data = Local<External>::Cast(callback_data->Get(OTAC_Data))->Value();
@@ -1885,7 +2578,7 @@ bool V8_AccesCheckCallback(
 	else
 		callback_info.key = new_V8_Value(the_engine, key);
 
-	return go_access_check_callback(typ, &callback_info, callback_info.engine);
+	return go_access_check_callback(typ, &callback_info, context_ptr);
 }
 
 bool V8_NamedSecurityCallback(Local<Object> host, Local<Value> key, AccessType type, Local<Value> data){
@@ -1963,6 +2656,8 @@ void V8_DisposeFunctionTemplate(void* tpl) {
 void* V8_FunctionTemplate_GetFunction(void* tpl) {
 	FUNCTION_TEMPLATE_SCOPE(tpl);
 	V8_Context* the_context = V8_Current_Context(isolate);
+	if (the_context == NULL)
+		return NULL;
 	return new_V8_Value(the_context, local_template->GetFunction());
 }
 
@@ -1978,6 +2673,11 @@ void* V8_FunctionTemplate_InstanceTemplate(void* tpl) {
 	return new V8_ObjectTemplate(the_template->engine, local_template->InstanceTemplate());
 }
 
+void* V8_FunctionTemplate_PrototypeTemplate(void* tpl) {
+	FUNCTION_TEMPLATE_HANDLE_SCOPE(tpl);
+	return new V8_ObjectTemplate(the_template->engine, local_template->PrototypeTemplate());
+}
+
 void V8_FunctionTemplate_SetHiddenPrototype(void* tpl, int value){
 	FUNCTION_TEMPLATE_HANDLE_SCOPE(tpl);
 	local_template->SetHiddenPrototype(value == 1);
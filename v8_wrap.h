@@ -59,6 +59,40 @@ typedef struct {
         void*     returnValue;
 } V8_PropertyCallbackInfo;
 
+typedef struct {
+        int       max_young_space_size;
+        int       max_old_space_size;
+        int       code_range_size;
+        int       stack_limit;
+        const char* snapshot_data;
+        int       snapshot_size;
+} V8_EngineOptions;
+
+typedef struct {
+        const char* data;
+        int       size;
+} V8_StartupData;
+
+typedef struct {
+        size_t    total_heap_size;
+        size_t    total_heap_size_executable;
+        size_t    total_physical_size;
+        size_t    total_available_size;
+        size_t    used_heap_size;
+        size_t    heap_size_limit;
+        size_t    malloced_memory;
+        size_t    peak_malloced_memory;
+        int64_t   external_memory;
+} V8_HeapStatistics;
+
+typedef struct {
+        const char* space_name;
+        size_t      space_size;
+        size_t      space_used_size;
+        size_t      space_available_size;
+        size_t      physical_space_size;
+} V8_HeapSpaceStatistics;
+
 typedef struct{
   void*   engine;
   void*   host;
@@ -78,10 +112,49 @@ engine
 */
 extern void* V8_NewEngine();
 
+extern void* V8_NewEngineWithOptions(V8_EngineOptions* options);
+
+extern void* V8_CreateSnapshot(const char** scripts, int* script_lengths, int count, V8_StartupData* blob);
+
+extern void V8_DeleteSnapshot(V8_StartupData* blob);
+
+extern int V8_Engine_TakeHeapLimitReached(void* engine);
+
+extern void V8_Engine_TerminateExecution(void* engine);
+
+extern void V8_Engine_CancelTerminateExecution(void* engine);
+
+extern int V8_Engine_IsExecutionTerminating(void* engine);
+
+extern void V8_Engine_GetHeapStatistics(void* engine, V8_HeapStatistics* stats);
+
+extern int V8_Engine_NumberOfHeapSpaces(void* engine);
+
+extern int V8_Engine_GetHeapSpaceStatistics(void* engine, V8_HeapSpaceStatistics* stats, int index);
+
+/*
+profiler
+*/
+extern void V8_Engine_SetCpuProfilerSamplingInterval(void* engine, int us);
+
+extern void V8_Engine_StartCpuProfile(void* engine, const char* title, int title_length);
+
+extern int V8_Engine_StopCpuProfile(void* engine, void* go_profile);
+
+extern int V8_Engine_TakeHeapSnapshot(void* engine, void* go_writer);
+
+extern int V8_Engine_StartSamplingHeapProfiler(void* engine, uint64_t interval, int stack_depth);
+
+extern void V8_Engine_StopSamplingHeapProfiler(void* engine);
+
+extern int V8_Engine_GetAllocationProfile(void* engine, void* go_profile);
+
 extern void V8_DisposeEngine(void* engine);
 
 extern void* V8_ParseJSON(void* context, const char* json, int json_length);
 
+extern void* V8_StringifyJSON(void* context, void* value, const char* gap, int gap_length);
+
 extern void V8_ForceGC(void* engine);
 
 /*
@@ -99,6 +172,12 @@ extern void V8_Context_Scope(void* context, void* context_ptr, void* callback);
 
 extern void* V8_Context_Global(void* context);
 
+extern int V8_Engine_InContextScope(void* engine);
+
+extern void* V8_Serialize(void* engine, void* value, void* go_engine, int* size);
+
+extern void* V8_Deserialize(void* engine, const char* data, int size, void* go_deserializer);
+
 extern void V8_Context_ThrowException(void* context, const char* err, int err_length);
 
 extern void V8_Context_ThrowException2(void* value);
@@ -185,6 +264,8 @@ extern int V8_Value_IsNativeError(void* value);
 
 extern int V8_Value_IsRegExp(void* value);
 
+extern int V8_Value_StrictEquals(void* value, void* other);
+
 extern int V8_Value_ToBoolean(void* value);
 
 extern double V8_Value_ToNumber(void* value);
@@ -195,6 +276,10 @@ extern uint32_t V8_Value_ToUint32(void* value);
 
 extern int32_t V8_Value_ToInt32(void* value);
 
+extern int V8_String_Length(void* value);
+
+extern int V8_String_Write(void* value, uint16_t* buffer, int start, int length);
+
 extern char* V8_Value_ToString(void* value);
 
 extern void V8_Value_SetFieldOwnerInfo(void* value, void* engine, int64_t ownerId);
@@ -344,6 +429,8 @@ extern void* V8_NewFunction(void* engine, void* callback, void* data);
 
 extern void* V8_Function_Call(void* value, int argc, void* argv);
 
+extern void* V8_Function_CallWithReceiver(void* value, void* recv, int argc, void* argv);
+
 extern void* V8_Function_NewInstance(void* value, int argc, void* argv);
 
 extern void* V8_FunctionCallbackInfo_Get(void* info, int i);
@@ -352,6 +439,8 @@ extern int V8_FunctionCallbackInfo_Length(void* info);
 
 extern void* V8_FunctionCallbackInfo_Callee(void* info);
 
+extern void* V8_FunctionCallbackInfo_NewTarget(void* info);
+
 extern void* V8_FunctionCallbackInfo_This(void* info);
 
 extern void* V8_FunctionCallbackInfo_Holder(void* info);
@@ -369,6 +458,8 @@ extern void V8_ObjectTemplate_Plugin(void* tpl, void* init);
 
 extern void V8_ObjectTemplate_SetProperty(void* tpl, const char* key, int key_length, void* prop_value, int attribs);
 
+extern void V8_ObjectTemplate_SetFunctionTemplate(void* tpl, const char* key, int key_length, void* func_tpl, int attribs);
+
 extern void* V8_ObjectTemplate_NewInstance(void* engine, void* tpl);
 
 extern void V8_ObjectTemplate_SetAccessor(void *tpl, const char* key, int key_length, void* getter, void* setter, void* data, int attribs);
@@ -415,6 +506,8 @@ extern void V8_FunctionTemplate_SetClassName(void* tpl, const char* name, int na
 
 extern void* V8_FunctionTemplate_InstanceTemplate(void* tpl);
 
+extern void* V8_FunctionTemplate_PrototypeTemplate(void* tpl);
+
 extern void V8_FunctionTemplate_SetHiddenPrototype(void* tpl, int value);
 
 /*